@@ -3,7 +3,7 @@ package db
 import (
 	"strings"
 
-	"github.com/ShrewdSpirit/su/errors"
+	"github.com/kiyoptr/su/errors"
 )
 
 func ErrOpen(err error) error {
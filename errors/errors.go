@@ -4,8 +4,10 @@ import (
 	"errors"
 	goerrors "errors"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
 // Error is a lightweight error struct with context
@@ -13,13 +15,51 @@ type Error struct {
 	Source  string
 	Message error
 	Inner   error
+	Kind    Kind // category of the error, Unknown if it isn't set
+
+	stack  []uintptr // program counters of the callers when the error was created
+	fields []field
+}
+
+// Frame is a function call in the stack of an Error
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) String() string { return fmt.Sprintf("%v\n\t%v:%v", f.Function, f.File, f.Line) }
+
+// stackDepth is the number of frames captured by new errors
+var stackDepth int32 = 32
+
+// SetStackDepth sets the number of frames captured by errors created afterwards. Zero disables capturing the stack,
+// which makes creating errors cheaper in hot paths. Source is recorded either way.
+func SetStackDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	atomic.StoreInt32(&stackDepth, int32(depth))
 }
 
+// NewError constructs an Error and captures the stack of its caller
 func NewError(source string, message, inner error) *Error {
 	return &Error{
 		Source:  source,
 		Message: message,
 		Inner:   inner,
+		stack:   callers(1),
+	}
+}
+
+// newError constructs an Error with the source and stack of the function that called the New function skip frames
+// above newError
+func newError(skip int, message, inner error) *Error {
+	return &Error{
+		Source:  getCallerInfo(skip + 1),
+		Message: message,
+		Inner:   inner,
+		stack:   callers(skip + 2),
 	}
 }
 
@@ -73,6 +113,101 @@ func (e *Error) Error() string {
 // Unwrap returns the inner error
 func (e *Error) Unwrap() error { return e.Inner }
 
+// OneLine returns the messages of the chain of err joined by ": ", without the sources of Errors, with newlines
+// escaped so it fits in one line of a log
+func OneLine(err error) string {
+	var messages []string
+	for ; err != nil; err = Unwrap(err) {
+		if e, ok := err.(*Error); ok {
+			if e.Message != nil {
+				messages = append(messages, e.Message.Error())
+			}
+			continue
+		}
+
+		message := err.Error()
+		if inner := Unwrap(err); inner != nil {
+			// wrappers like fmt.Errorf("load: %w", err) contain the text of the error they wrap, sources included
+			message = strings.Replace(message, inner.Error(), OneLine(inner), 1)
+		}
+		messages = append(messages, message)
+		break
+	}
+
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(strings.Join(messages, ": "))
+}
+
+// WithKind sets the kind of the error, which KindOf finds for it and the errors wrapping it
+func (e *Error) WithKind(kind Kind) *Error {
+	e.Kind = kind
+	return e
+}
+
+// Frames resolves the stack captured when the error was created, starting from the function that created it.
+// It's empty if capturing the stack was disabled by SetStackDepth.
+func (e *Error) Frames() []Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	list := make([]Frame, 0, len(e.stack))
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		f, more := frames.Next()
+		list = append(list, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			return list
+		}
+	}
+}
+
+// Format writes the stack trace of the error for %v and %s, and adds the fields and frames of each Error in it for %+v
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('#'):
+		type plain Error
+		fmt.Fprintf(s, "%#v", (*plain)(e))
+	case verb == 'v' && s.Flag('+'):
+		first := true
+		e.Each(func(err error) bool {
+			if !first {
+				io.WriteString(s, "\n")
+			}
+			first = false
+
+			cast, ok := err.(*Error)
+			if !ok {
+				io.WriteString(s, err.Error())
+				return true
+			}
+
+			io.WriteString(s, cast.String())
+			for _, f := range cast.fields {
+				fmt.Fprintf(s, " [%v=%v]", f.key, f.value)
+			}
+			for _, f := range cast.Frames() {
+				io.WriteString(s, "\n\t"+strings.Replace(f.String(), "\n", "\n\t", -1))
+			}
+			return true
+		})
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// callers returns the program counters of the stack above the caller of callers, skipping skip more frames
+func callers(skip int) []uintptr {
+	depth := atomic.LoadInt32(&stackDepth)
+	if depth == 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, depth)
+	return pcs[:runtime.Callers(2+skip, pcs)]
+}
+
 // getCallerInfo returns the file and line that called any of New functions as string
 // skipFrames parameter defines how many functions to skip
 func getCallerInfo(skipFrames int) string {
@@ -85,41 +220,41 @@ func getCallerInfo(skipFrames int) string {
 }
 
 // New constructs a new Error
-func New(msg string) error {
-	return NewError(getCallerInfo(0), errors.New(msg), nil)
+func New(msg string) *Error {
+	return newError(0, errors.New(msg), nil)
 }
 
 // Newi attaches an existing error to a new error
 // This is used to provide an easier way for wrapping errors and stack trace
-func Newi(inner error, msg string) error {
-	return NewError(getCallerInfo(0), errors.New(msg), inner)
+func Newi(inner error, msg string) *Error {
+	return newError(0, errors.New(msg), inner)
 }
 
 // Newf constructs a formatted error
-func Newf(format string, params ...interface{}) error {
-	return NewError(getCallerInfo(0), errors.New(fmt.Sprintf(format, params...)), nil)
+func Newf(format string, params ...interface{}) *Error {
+	return newError(0, errors.New(fmt.Sprintf(format, params...)), nil)
 }
 
 // Newif constructs a new formatted error with an attached inner error
-func Newif(inner error, format string, params ...interface{}) error {
-	return NewError(getCallerInfo(0), errors.New(fmt.Sprintf(format, params...)), inner)
+func Newif(inner error, format string, params ...interface{}) *Error {
+	return newError(0, errors.New(fmt.Sprintf(format, params...)), inner)
 }
 
 // News constructs a new Error and skips given frames for getting stack info.
-func News(skip int, msg string) error {
-	return NewError(getCallerInfo(skip), errors.New(msg), nil)
+func News(skip int, msg string) *Error {
+	return newError(skip, errors.New(msg), nil)
 }
 
-func Newsi(skip int, inner error, msg string) error {
-	return NewError(getCallerInfo(skip), errors.New(msg), inner)
+func Newsi(skip int, inner error, msg string) *Error {
+	return newError(skip, errors.New(msg), inner)
 }
 
-func Newsf(skip int, format string, params ...interface{}) error {
-	return NewError(getCallerInfo(skip), errors.New(fmt.Sprintf(format, params...)), nil)
+func Newsf(skip int, format string, params ...interface{}) *Error {
+	return newError(skip, errors.New(fmt.Sprintf(format, params...)), nil)
 }
 
-func Newsif(skip int, inner error, format string, params ...interface{}) error {
-	return NewError(getCallerInfo(skip), errors.New(fmt.Sprintf(format, params...)), inner)
+func Newsif(skip int, inner error, format string, params ...interface{}) *Error {
+	return newError(skip, errors.New(fmt.Sprintf(format, params...)), inner)
 }
 
 // As is a wrapper around go's standard errors.As
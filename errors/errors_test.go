@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -16,3 +18,61 @@ func TestHasError(t *testing.T) {
 
 	t.Log(err)
 }
+
+func newNested() error { return Newi(io.EOF, "nested") }
+
+func TestError_Frames(t *testing.T) {
+	var e *Error
+	if !As(newNested(), &e) {
+		t.Fatal("expected *Error")
+	}
+
+	frames := e.Frames()
+	if len(frames) < 2 || !strings.HasSuffix(frames[0].Function, ".newNested") ||
+		!strings.HasSuffix(frames[1].Function, ".TestError_Frames") || !strings.HasSuffix(frames[0].File, "errors_test.go") {
+		t.Fatalf("expected frames of newNested and the test, got %v", frames)
+	}
+	if !strings.HasSuffix(e.Source, fmt.Sprintf("%v:%v", frames[0].File, frames[0].Line)) {
+		t.Errorf("expected source %v to be the first frame", e.Source)
+	}
+
+	if trace := fmt.Sprintf("%+v", e); !strings.Contains(trace, "nested\n\t") || !strings.Contains(trace, "TestError_Frames\n\t\t") {
+		t.Errorf("expected frames in %q", trace)
+	}
+	if msg := fmt.Sprintf("%v", e); msg != e.Error() {
+		t.Errorf("expected %q, got %q", e.Error(), msg)
+	}
+}
+
+func TestSetStackDepth(t *testing.T) {
+	defer SetStackDepth(32)
+
+	SetStackDepth(1)
+	if frames := New("one").Frames(); len(frames) != 1 || !strings.HasSuffix(frames[0].Function, ".TestSetStackDepth") {
+		t.Errorf("expected the frame of the test, got %v", frames)
+	}
+
+	SetStackDepth(0)
+	if e := New("none"); e.Frames() != nil || !strings.Contains(e.Source, "errors_test.go") {
+		t.Errorf("expected only a source, got %v and %v", e.Source, e.Frames())
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	inner := Newi(io.EOF, "user\nnot found")
+	err := Newi(fmt.Errorf("query: %w", inner), "failed to load profile")
+
+	for _, c := range []struct {
+		err      error
+		expected string
+	}{
+		{err, `failed to load profile: query: user\nnot found: EOF`},
+		{fmt.Errorf("%w, giving up", inner), `user\nnot found: EOF, giving up`},
+		{io.EOF, "EOF"},
+		{nil, ""},
+	} {
+		if line := OneLine(c.err); line != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, line)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package errors
+
+// field is a key and value attached to an Error by With
+type field struct {
+	key   string
+	value interface{}
+}
+
+// With attaches a key and value to the error, like the id of the entity it's about. Fields are kept out of the
+// message so errors can be grouped by their message and searched by their fields. Setting a key again replaces its
+// value.
+func (e *Error) With(key string, value interface{}) *Error {
+	for i := range e.fields {
+		if e.fields[i].key == key {
+			e.fields[i].value = value
+			return e
+		}
+	}
+
+	e.fields = append(e.fields, field{key: key, value: value})
+	return e
+}
+
+// Fields collects the fields of all errors in the chain of err. If a key is set by several of them, the value of the
+// outermost one is kept. It returns nil if there are no fields.
+func Fields(err error) map[string]interface{} {
+	var chain []*Error
+	for ; err != nil; err = Unwrap(err) {
+		if e, ok := err.(*Error); ok && len(e.fields) > 0 {
+			chain = append(chain, e)
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{})
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, f := range chain[i].fields {
+			fields[f.key] = f.value
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,33 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	inner := Newi(io.EOF, "user not found").With("user_id", 42).With("table", "users")
+	err := Newi(fmt.Errorf("query: %w", inner), "failed to load profile").With("user_id", 7).With("request", "abc")
+
+	expected := map[string]interface{}{"user_id": 7, "table": "users", "request": "abc"}
+	if fields := Fields(err); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+	if fields := Fields(New("plain")); fields != nil {
+		t.Errorf("expected no fields, got %v", fields)
+	}
+	if fields := Fields(New("again").With("n", 1).With("n", 2)); !reflect.DeepEqual(fields, map[string]interface{}{"n": 2}) {
+		t.Errorf("expected the last value, got %v", fields)
+	}
+
+	// fields aren't part of the message
+	if strings.Contains(err.Error(), "user_id") {
+		t.Errorf("expected no fields in %q", err.Error())
+	}
+	if trace := fmt.Sprintf("%+v", err); !strings.Contains(trace, "failed to load profile [user_id=7] [request=abc]\n") {
+		t.Errorf("expected fields after the message in %q", trace)
+	}
+}
@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is the JSON body of an error response as described by RFC 7807
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Kind   string `json:"kind"`
+}
+
+// NewProblem describes err for a response with the status of its kind. The detail is the message of the error that
+// has the kind, without its source. It's left out for Unknown and Internal errors, since their messages aren't meant
+// for clients.
+func NewProblem(err error) Problem {
+	kind, cause := kindOf(err)
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(kind.HTTPStatus()),
+		Status: kind.HTTPStatus(),
+		Kind:   kind.String(),
+	}
+	if p.Title == "" {
+		p.Title = kind.String()
+	}
+
+	if kind != Unknown && kind != Internal {
+		if e, ok := cause.(*Error); ok && e.Message != nil {
+			p.Detail = e.Message.Error()
+		} else {
+			p.Detail = cause.Error()
+		}
+	}
+
+	return p
+}
+
+// WriteProblem writes err to w as an application/problem+json response with the status of its kind
+func WriteProblem(w http.ResponseWriter, err error) {
+	p := NewProblem(err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	for i, c := range []struct {
+		err      error
+		status   int
+		expected string
+	}{
+		{
+			NewKindi(NotFound, New("no rows"), "user 42 not found"),
+			http.StatusNotFound,
+			`{"type":"about:blank","title":"Not Found","status":404,"detail":"user 42 not found","kind":"NotFound"}`,
+		},
+		{
+			NewKindi(Internal, New("password is admin"), "connection failed"),
+			http.StatusInternalServerError,
+			`{"type":"about:blank","title":"Internal Server Error","status":500,"kind":"Internal"}`,
+		},
+		{
+			NewKind(Canceled, "client left"),
+			499,
+			`{"type":"about:blank","title":"Canceled","status":499,"detail":"client left","kind":"Canceled"}`,
+		},
+	} {
+		w := httptest.NewRecorder()
+		WriteProblem(w, c.err)
+		if w.Code != c.status || w.Header().Get("Content-Type") != "application/problem+json" || w.Body.String() != c.expected+"\n" {
+			t.Errorf("%d: expected %d %s, got %d %s", i, c.status, c.expected, w.Code, w.Body)
+		}
+	}
+}
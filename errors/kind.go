@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind is the category of an error, which tells callers and other services how to handle it without matching its
+// message. It's attached by NewKind and its variants or Error.WithKind, and found by KindOf.
+type Kind int
+
+const (
+	Unknown Kind = iota
+	InvalidArgument
+	NotFound
+	AlreadyExists
+	Conflict
+	Unauthorized
+	Forbidden
+	ResourceExhausted
+	FailedPrecondition
+	Canceled
+	Timeout
+	Unimplemented
+	Internal
+	Unavailable
+)
+
+// kinds holds the name, HTTP status and gRPC code of each kind
+var kinds = [...]struct {
+	name string
+	http int
+	grpc int
+}{
+	Unknown:            {"Unknown", http.StatusInternalServerError, 2},
+	InvalidArgument:    {"InvalidArgument", http.StatusBadRequest, 3},
+	NotFound:           {"NotFound", http.StatusNotFound, 5},
+	AlreadyExists:      {"AlreadyExists", http.StatusConflict, 6},
+	Conflict:           {"Conflict", http.StatusConflict, 10},
+	Unauthorized:       {"Unauthorized", http.StatusUnauthorized, 16},
+	Forbidden:          {"Forbidden", http.StatusForbidden, 7},
+	ResourceExhausted:  {"ResourceExhausted", http.StatusTooManyRequests, 8},
+	FailedPrecondition: {"FailedPrecondition", http.StatusBadRequest, 9},
+	Canceled:           {"Canceled", 499, 1},
+	Timeout:            {"Timeout", http.StatusGatewayTimeout, 4},
+	Unimplemented:      {"Unimplemented", http.StatusNotImplemented, 12},
+	Internal:           {"Internal", http.StatusInternalServerError, 13},
+	Unavailable:        {"Unavailable", http.StatusServiceUnavailable, 14},
+}
+
+// valid returns k if it's one of the kinds and Unknown otherwise
+func (k Kind) valid() Kind {
+	if k < 0 || int(k) >= len(kinds) {
+		return Unknown
+	}
+	return k
+}
+
+func (k Kind) String() string { return kinds[k.valid()].name }
+
+// HTTPStatus returns the HTTP status code of the kind. Canceled maps to 499, the status that's commonly used for
+// requests that the client closed.
+func (k Kind) HTTPStatus() int { return kinds[k.valid()].http }
+
+// GRPCCode returns the number of the gRPC status code of the kind, which can be converted to codes.Code
+func (k Kind) GRPCCode() int { return kinds[k.valid()].grpc }
+
+// NewKind constructs an Error of kind
+func NewKind(kind Kind, msg string) *Error {
+	return newError(0, errors.New(msg), nil).WithKind(kind)
+}
+
+// NewKindi wraps inner in a new Error of kind
+func NewKindi(kind Kind, inner error, msg string) *Error {
+	return newError(0, errors.New(msg), inner).WithKind(kind)
+}
+
+// NewKindf constructs a formatted Error of kind
+func NewKindf(kind Kind, format string, params ...interface{}) *Error {
+	return newError(0, errors.New(fmt.Sprintf(format, params...)), nil).WithKind(kind)
+}
+
+// NewKindif wraps inner in a new formatted Error of kind
+func NewKindif(kind Kind, inner error, format string, params ...interface{}) *Error {
+	return newError(0, errors.New(fmt.Sprintf(format, params...)), inner).WithKind(kind)
+}
+
+// KindOf returns the kind of the outermost Error in the chain of err that has one. Errors of a context are Canceled
+// and Timeout. It's Unknown if err is nil or nothing in its chain has a kind.
+func KindOf(err error) Kind {
+	kind, _ := kindOf(err)
+	return kind
+}
+
+// kindOf returns the kind of err and the error in its chain that the kind was found on
+func kindOf(err error) (Kind, error) {
+	for ; err != nil; err = Unwrap(err) {
+		switch e := err.(type) {
+		case *Error:
+			if e.Kind != Unknown {
+				return e.Kind, e
+			}
+		default:
+			if err == context.Canceled {
+				return Canceled, err
+			} else if err == context.DeadlineExceeded {
+				return Timeout, err
+			}
+		}
+	}
+
+	return Unknown, nil
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	notFound := NewKindi(NotFound, io.EOF, "user not found")
+
+	for i, c := range []struct {
+		err      error
+		expected Kind
+	}{
+		{nil, Unknown},
+		{io.EOF, Unknown},
+		{New("failed"), Unknown},
+		{notFound, NotFound},
+		{Newi(notFound, "failed to load profile"), NotFound},
+		{fmt.Errorf("handler: %w", notFound), NotFound},
+		{NewKindi(Unavailable, notFound, "store is down"), Unavailable},
+		{Newi(context.DeadlineExceeded, "query"), Timeout},
+		{NewKindi(Internal, context.Canceled, "query"), Internal},
+	} {
+		if kind := KindOf(c.err); kind != c.expected {
+			t.Errorf("%d: expected %v, got %v", i, c.expected, kind)
+		}
+	}
+}
+
+func TestKind_Codes(t *testing.T) {
+	for _, c := range []struct {
+		kind Kind
+		http int
+		grpc int
+	}{
+		{Unknown, http.StatusInternalServerError, 2},
+		{NotFound, http.StatusNotFound, 5},
+		{Conflict, http.StatusConflict, 10},
+		{Unauthorized, http.StatusUnauthorized, 16},
+		{Unavailable, http.StatusServiceUnavailable, 14},
+		{Kind(100), http.StatusInternalServerError, 2},
+	} {
+		if c.kind.HTTPStatus() != c.http || c.kind.GRPCCode() != c.grpc {
+			t.Errorf("%v: expected %d and %d, got %d and %d", c.kind, c.http, c.grpc, c.kind.HTTPStatus(), c.kind.GRPCCode())
+		}
+	}
+}
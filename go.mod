@@ -5,5 +5,5 @@ go 1.13
 require (
 	github.com/google/uuid v1.1.1
 	github.com/jinzhu/gorm v1.9.12
-	github.com/mitchellh/go-homedir v1.1.0 // indirect
+	github.com/mitchellh/go-homedir v1.1.0
 )
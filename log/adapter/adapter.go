@@ -4,3 +4,9 @@ type Adapter interface {
 	Write(message string)
 	Close() error
 }
+
+// RawWriter is implemented by adapters that can write an entry without converting it to string.
+// p ends with a newline and is only valid until WriteRaw returns.
+type RawWriter interface {
+	WriteRaw(p []byte)
+}
@@ -34,6 +34,10 @@ func (f *file) Write(message string) {
 	fmt.Fprintln(f.output, message)
 }
 
+func (f *file) WriteRaw(p []byte) {
+	f.output.Write(p)
+}
+
 func (f *file) Close() error {
 	if f.customFile {
 		return f.output.Close()
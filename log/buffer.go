@@ -0,0 +1,70 @@
+package log
+
+import "sync"
+
+// buffer is a reusable byte slice for building a log entry
+type buffer struct {
+	b []byte
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &buffer{b: make([]byte, 0, 256)}
+	},
+}
+
+func getBuffer() *buffer {
+	b := bufferPool.Get().(*buffer)
+	b.b = b.b[:0]
+	return b
+}
+
+func putBuffer(b *buffer) {
+	// don't keep huge buffers around
+	if cap(b.b) > 64*1024 {
+		return
+	}
+	bufferPool.Put(b)
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.b = append(b.b, p...)
+	return len(p), nil
+}
+
+// beginTag writes the separator and the key part of a tag
+func (b *buffer) beginTag(key string) {
+	if len(b.b) > 0 {
+		b.b = append(b.b, ' ')
+	}
+	b.b = append(b.b, '[')
+	b.b = append(b.b, key...)
+	b.b = append(b.b, '=')
+}
+
+func (b *buffer) endTag() {
+	b.b = append(b.b, ']')
+}
+
+func (b *buffer) appendTag(key, value string) {
+	b.beginTag(key)
+	b.b = append(b.b, value...)
+	b.endTag()
+}
+
+func (b *buffer) appendField(f *Field) {
+	b.beginTag(f.Key)
+	f.appendValue(b)
+	b.endTag()
+}
+
+// appendRaw appends an already rendered run of tags
+func (b *buffer) appendRaw(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	if len(b.b) > 0 {
+		b.b = append(b.b, ' ')
+	}
+	b.b = append(b.b, p...)
+}
@@ -2,29 +2,36 @@ package log
 
 import (
 	"errors"
+	"sync"
+
 	"github.com/kiyoptr/su/log/adapter"
 	"github.com/kiyoptr/su/log/tagprovider"
-	"sync"
 )
 
 type Builder struct {
 	adapters []adapter.Adapter
-	tl       taglist
+	name     string
+	mode     Mode
+	segments []segment
+}
+
+// segment is either a pre-rendered run of static tags or a dynamic tag provider
+type segment struct {
+	raw      []byte
+	provider tagprovider.Provider
 }
 
 func New() *Builder {
-	return &Builder{
-		tl: newTagList(),
-	}
+	return &Builder{}
 }
 
 func (b *Builder) Name(name string) *Builder {
-	b.tl.set(toName, tagprovider.Constant("name", name))
+	b.name = name
 	return b
 }
 
 func (b *Builder) WithDefaultMode(mode Mode) *Builder {
-	b.tl.set(toMode, tagprovider.Constant("mode", mode))
+	b.mode = mode
 	return b
 }
 
@@ -38,9 +45,28 @@ func (b *Builder) WithAdapters(a ...adapter.Adapter) *Builder {
 	return b
 }
 
+// WithTags adds dynamic tags. Providers are called on every write.
 func (b *Builder) WithTags(providers ...tagprovider.Provider) *Builder {
 	for _, p := range providers {
-		b.tl.set(toCustom, p)
+		b.segments = append(b.segments, segment{provider: p})
+	}
+
+	return b
+}
+
+// WithFields adds static tags. They are rendered once when the logger is built.
+func (b *Builder) WithFields(fields ...Field) *Builder {
+	buf := &buffer{}
+	for i := range fields {
+		buf.appendField(&fields[i])
+	}
+
+	// merge with the previous static run so they're written at once
+	if n := len(b.segments); n > 0 && b.segments[n-1].provider == nil {
+		last := &b.segments[n-1]
+		last.raw = append(append(last.raw, ' '), buf.b...)
+	} else {
+		b.segments = append(b.segments, segment{raw: buf.b})
 	}
 
 	return b
@@ -57,10 +83,16 @@ func (b *Builder) Build() (l *Logger, err error) {
 	}
 
 	l = &Logger{
-		staticTags: b.tl,
-		customTags: newTagList(),
-		adapters:   b.adapters,
-		lock:       sync.Mutex{},
+		mode:     b.mode,
+		segments: b.segments,
+		adapters: b.adapters,
+		lock:     sync.Mutex{},
+	}
+
+	if b.name != "" {
+		buf := &buffer{}
+		buf.appendTag("name", b.name)
+		l.name = buf.b
 	}
 
 	return
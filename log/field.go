@@ -0,0 +1,163 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	fkString fieldKind = iota
+	fkInt
+	fkDuration
+	fkAny
+)
+
+// Field is a typed tag. Use String, Int and Duration to build tags without boxing the value in an interface.
+type Field struct {
+	Key   string
+	kind  fieldKind
+	str   string
+	num   int64
+	value interface{}
+}
+
+func String(key, value string) Field {
+	return Field{Key: key, kind: fkString, str: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: fkInt, num: int64(value)}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: fkDuration, num: int64(value)}
+}
+
+// Any creates a field from an arbitrary value. It is formatted with %v and allocates on every write.
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case Mode:
+		return String(key, string(v))
+	case int:
+		return Int(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	}
+
+	return Field{Key: key, kind: fkAny, value: value}
+}
+
+func (f *Field) appendValue(b *buffer) {
+	switch f.kind {
+	case fkString:
+		b.b = append(b.b, f.str...)
+	case fkInt:
+		b.b = strconv.AppendInt(b.b, f.num, 10)
+	case fkDuration:
+		b.b = appendDuration(b.b, time.Duration(f.num))
+	default:
+		fmt.Fprintf(b, "%v", f.value)
+	}
+}
+
+// appendDuration formats d the same way time.Duration.String does without allocating.
+func appendDuration(dst []byte, d time.Duration) []byte {
+	var buf [32]byte
+	w := len(buf)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		var prec int
+		w--
+		buf[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			return append(dst, "0s"...)
+		case u < uint64(time.Microsecond):
+			prec = 0
+			buf[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			// U+00B5 'µ' micro sign is 0xC2 0xB5
+			w--
+			copy(buf[w:], "µ")
+		default:
+			prec = 6
+			buf[w] = 'm'
+		}
+		w, u = fmtFrac(buf[:w], u, prec)
+		w = fmtInt(buf[:w], u)
+	} else {
+		w--
+		buf[w] = 's'
+
+		w, u = fmtFrac(buf[:w], u, 9)
+		w = fmtInt(buf[:w], u%60)
+		u /= 60
+
+		if u > 0 {
+			w--
+			buf[w] = 'm'
+			w = fmtInt(buf[:w], u%60)
+			u /= 60
+
+			if u > 0 {
+				w--
+				buf[w] = 'h'
+				w = fmtInt(buf[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		buf[w] = '-'
+	}
+
+	return append(dst, buf[w:]...)
+}
+
+func fmtFrac(buf []byte, v uint64, prec int) (nw int, nv uint64) {
+	w := len(buf)
+	print := false
+	for i := 0; i < prec; i++ {
+		digit := v % 10
+		print = print || digit != 0
+		if print {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if print {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+func fmtInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+	} else {
+		for v > 0 {
+			w--
+			buf[w] = byte(v%10) + '0'
+			v /= 10
+		}
+	}
+	return w
+}
@@ -2,9 +2,12 @@ package log
 
 import (
 	"fmt"
-	"github.com/kiyoptr/su/log/adapter"
-	"github.com/kiyoptr/su/log/tagprovider"
+	"sort"
+	"strings"
 	"sync"
+
+	"github.com/kiyoptr/su/errors"
+	"github.com/kiyoptr/su/log/adapter"
 )
 
 var (
@@ -20,10 +23,15 @@ func Instance() *Logger { return instance }
 
 // Logger is a thread-safe logging type
 type Logger struct {
-	staticTags taglist
-	customTags taglist
-	adapters   []adapter.Adapter
-	lock       sync.Mutex
+	name     []byte // rendered name tag
+	mode     Mode   // default mode
+	segments []segment
+	adapters []adapter.Adapter
+	lock     sync.Mutex
+
+	// tags for the next write. they're reset after each write
+	nextMode Mode
+	fields   []Field
 }
 
 func (l *Logger) Close() error {
@@ -39,34 +47,140 @@ func (l *Logger) Close() error {
 func (l *Logger) Mode(mode Mode) *Logger {
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	l.customTags.set(toMode, tagprovider.Constant("mode", mode))
+	l.nextMode = mode
 	return l
 }
 
+// Tag adds a tag to the next write. Prefer Fields with typed fields in hot paths.
 func (l *Logger) Tag(key string, value interface{}) *Logger {
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	l.customTags.set(toCustom, tagprovider.Constant(key, value))
+	l.fields = append(l.fields, Any(key, value))
+	return l
+}
+
+// Fields adds typed tags to the next write.
+func (l *Logger) Fields(fields ...Field) *Logger {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.fields = append(l.fields, fields...)
 	return l
 }
 
+// Err adds the tags of err to the next write. See ErrorFields.
+func (l *Logger) Err(err error) *Logger {
+	return l.Fields(ErrorFields(err)...)
+}
+
+// ErrorFields returns the tags of err: its messages in one line as the error tag, the sources of the Errors in its
+// chain as the stack tag and its fields (see errors.With) ordered by key. It returns nil if err is nil.
+func ErrorFields(err error) []Field {
+	if err == nil {
+		return nil
+	}
+
+	list := []Field{String("error", errors.OneLine(err))}
+
+	var sources []string
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if cast, ok := e.(*errors.Error); ok {
+			sources = append(sources, cast.Source)
+		}
+	}
+	if len(sources) > 0 {
+		list = append(list, String("stack", strings.Join(sources, ", ")))
+	}
+
+	fields := errors.Fields(err)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		list = append(list, Any(key, fields[key]))
+	}
+
+	return list
+}
+
 func (l *Logger) Write() {
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	l.write()
+
+	b := l.begin()
+	l.end(b)
+}
+
+// Print writes message without formatting it.
+func (l *Logger) Print(message string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	b := l.begin()
+	b.appendTag("message", message)
+	l.end(b)
 }
 
 func (l *Logger) Writef(format string, args ...interface{}) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	l.customTags.set(toMessage, tagprovider.Constant("message", fmt.Sprintf(format, args...)))
-	l.write()
+
+	b := l.begin()
+	b.beginTag("message")
+	fmt.Fprintf(b, format, args...)
+	b.endTag()
+	l.end(b)
 }
 
-func (l *Logger) write() {
-	t := l.staticTags.merge(l.customTags).build()
+// begin renders all tags except message into a pooled buffer
+func (l *Logger) begin() *buffer {
+	b := getBuffer()
+
+	b.appendRaw(l.name)
+
+	mode := l.mode
+	if l.nextMode != "" {
+		mode = l.nextMode
+	}
+	if mode != "" {
+		b.appendTag("mode", string(mode))
+	}
+
+	for _, s := range l.segments {
+		if s.provider != nil {
+			key, value := s.provider()
+			b.appendTag(key, value)
+		} else {
+			b.appendRaw(s.raw)
+		}
+	}
+
+	for i := range l.fields {
+		b.appendField(&l.fields[i])
+	}
+
+	return b
+}
+
+// end sends the entry to adapters and resets the tags of this write
+func (l *Logger) end(b *buffer) {
+	n := len(b.b)
+	b.b = append(b.b, '\n')
+
 	for _, a := range l.adapters {
-		a.Write(t)
+		if rw, ok := a.(adapter.RawWriter); ok {
+			rw.WriteRaw(b.b)
+		} else {
+			a.Write(string(b.b[:n]))
+		}
+	}
+
+	putBuffer(b)
+
+	l.nextMode = ""
+	for i := range l.fields {
+		l.fields[i] = Field{}
 	}
-	l.customTags = newTagList()
+	l.fields = l.fields[:0]
 }
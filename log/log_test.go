@@ -1,10 +1,15 @@
 package log
 
 import (
-	"github.com/kiyoptr/su/log/adapter"
-	"github.com/kiyoptr/su/log/tagprovider"
+	goerrors "errors"
+	"fmt"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/kiyoptr/su/errors"
+	"github.com/kiyoptr/su/log/adapter"
+	"github.com/kiyoptr/su/log/tagprovider"
 )
 
 func TestNew(t *testing.T) {
@@ -48,3 +53,100 @@ func TestThreadSafety(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+type captureAdapter struct {
+	lines []string
+}
+
+func (c *captureAdapter) Write(message string) { c.lines = append(c.lines, message) }
+func (c *captureAdapter) Close() error         { return nil }
+
+func TestFormat(t *testing.T) {
+	c := &captureAdapter{}
+	l, err := New().
+		WithAdapters(c).
+		WithFields(String("app", "su")).
+		WithTags(tagprovider.Constant("pid", 1)).
+		WithFields(Int("v", 2)).
+		Name("fmt").
+		WithDefaultMode(Info).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Mode(Warning).Tag("user", "kiyo").Fields(Int("n", -42), Duration("took", 1500*time.Millisecond)).Writef("done %d", 3)
+	l.Print("plain")
+
+	expected := []string{
+		"[name=fmt] [mode=warning] [app=su] [pid=1] [v=2] [user=kiyo] [n=-42] [took=1.5s] [message=done 3]",
+		"[name=fmt] [mode=info] [app=su] [pid=1] [v=2] [message=plain]",
+	}
+	if len(c.lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), len(c.lines))
+	}
+	for i := range expected {
+		if c.lines[i] != expected[i] {
+			t.Errorf("line %d:\nexpected %s\ngot      %s", i, expected[i], c.lines[i])
+		}
+	}
+}
+
+func TestLogger_Err(t *testing.T) {
+	c := &captureAdapter{}
+	l, err := New().WithAdapters(c).Name("err").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner := errors.New("not found").With("user_id", 42)
+	outer := errors.Newi(fmt.Errorf("load: %w", inner), "failed\nto load")
+	l.Err(goerrors.New("wrapped")).Print("first")
+	l.Err(outer).Print("second")
+	l.Err(nil).Print("third")
+
+	// errors with sources are written in one line
+	expected := []string{
+		"[name=err] [error=wrapped] [message=first]",
+		"[name=err] [error=failed\\nto load: load: not found] [stack=" + outer.Source + ", " + inner.Source + "] [user_id=42] [message=second]",
+		"[name=err] [message=third]",
+	}
+	for i := range expected {
+		if i >= len(c.lines) || c.lines[i] != expected[i] {
+			t.Errorf("line %d: expected %s, got %v", i, expected[i], c.lines)
+		}
+	}
+}
+
+func TestAppendDuration(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 999, time.Microsecond + 10, 15 * time.Millisecond, -time.Second, 90 * time.Minute, 26*time.Hour + 3*time.Second + 5} {
+		if s := string(appendDuration(nil, d)); s != d.String() {
+			t.Errorf("expected %s, got %s", d.String(), s)
+		}
+	}
+}
+
+type nopAdapter struct{}
+
+func (nopAdapter) Write(string)      {}
+func (nopAdapter) WriteRaw(p []byte) {}
+func (nopAdapter) Close() error      { return nil }
+
+// Before typed fields and pooled buffers this path (with Tag and Writef) took ~29 allocs/op.
+func BenchmarkLogger_Tag(b *testing.B) {
+	l, _ := New().Name("bench").WithAdapters(nopAdapter{}).WithDefaultMode(Info).Build()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Mode(Warning).Tag("user", "kiyo").Tag("n", 42).Writef("request done")
+	}
+}
+
+func BenchmarkLogger_Fields(b *testing.B) {
+	l, _ := New().Name("bench").WithAdapters(nopAdapter{}).WithDefaultMode(Info).WithFields(String("app", "su")).Build()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Mode(Warning).Fields(String("user", "kiyo"), Int("n", 42), Duration("took", time.Millisecond)).Print("request done")
+	}
+}
@@ -0,0 +1,194 @@
+package ticker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminHandler is an http.Handler that shows the tasks of a scheduler as JSON and acts on them:
+//
+//	GET  /tasks                list of the tasks ordered by their next run
+//	GET  /tasks/{task}         a task with its history
+//	POST /tasks/{task}/pause   Scheduler.Pause
+//	POST /tasks/{task}/resume  Scheduler.Resume
+//	POST /tasks/{task}/run     Scheduler.RunNow
+//	POST /tasks/{task}/trigger Scheduler.Trigger
+//	POST /tasks/{task}/remove  Scheduler.Remove
+//
+// remove answers 202 Accepted right after the task's runs are cancelled instead of waiting for them to return like
+// Scheduler.Remove. {task} is the id or the name of a task. Paths are relative to where the handler is mounted, so it's usually
+// wrapped in http.StripPrefix. Errors are JSON objects with an "error" field.
+type AdminHandler struct {
+	scheduler *Scheduler
+	auth      func(r *http.Request) error
+}
+
+func NewAdminHandler(s *Scheduler) *AdminHandler {
+	return &AdminHandler{scheduler: s}
+}
+
+// WithAuth checks each request with f before handling it. Requests that f returns an error for are refused with
+// 401 Unauthorized and the error's message.
+func (h *AdminHandler) WithAuth(f func(r *http.Request) error) *AdminHandler {
+	h.auth = f
+	return h
+}
+
+// AdminTask is the JSON form of a task returned by AdminHandler
+type AdminTask struct {
+	Id        uuid.UUID   `json:"id"`
+	Name      string      `json:"name,omitempty"`
+	Schedule  Schedule    `json:"schedule"`
+	NextRun   *time.Time  `json:"next_run,omitempty"`
+	LastRun   *time.Time  `json:"last_run,omitempty"`
+	LastError string      `json:"last_error,omitempty"`
+	Runs      int         `json:"runs"`
+	Failures  int         `json:"failures"`
+	Panics    int         `json:"panics"`
+	Skipped   int         `json:"skipped"`
+	Running   int         `json:"running"`
+	Paused    bool        `json:"paused"`
+	History   []RunRecord `json:"history,omitempty"`
+}
+
+func newAdminTask(i TaskInfo) AdminTask {
+	a := AdminTask{
+		Id:       i.Id,
+		Name:     i.Name,
+		Schedule: i.Schedule,
+		Runs:     i.Runs,
+		Failures: i.Failures,
+		Panics:   i.Panics,
+		Skipped:  i.Skipped,
+		Running:  i.Running,
+		Paused:   i.Paused,
+	}
+	if !i.NextRun.IsZero() {
+		a.NextRun = &i.NextRun
+	}
+	if !i.LastRun.IsZero() {
+		a.LastRun = &i.LastRun
+	}
+	if i.LastError != nil {
+		a.LastError = i.LastError.Error()
+	}
+
+	return a
+}
+
+var adminActions = map[string]func(s *Scheduler, id uuid.UUID) error{
+	"pause":   (*Scheduler).Pause,
+	"resume":  (*Scheduler).Resume,
+	"run":     (*Scheduler).RunNow,
+	"trigger": (*Scheduler).Trigger,
+	"remove":  (*Scheduler).removeNoWait,
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.auth != nil {
+		if err := h.auth(r); err != nil {
+			writeAdminError(w, http.StatusUnauthorized, err)
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "tasks" || len(parts) > 3 {
+		writeAdminError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			writeAdminError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		list := h.scheduler.Tasks()
+		tasks := make([]AdminTask, len(list))
+		for i, info := range list {
+			tasks[i] = newAdminTask(info)
+		}
+		writeAdminJSON(w, http.StatusOK, tasks)
+		return
+	}
+
+	info, ok := h.find(parts[1])
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, ErrTaskNotFound)
+		return
+	}
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodGet {
+			writeAdminError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		h.writeTask(w, info.Id)
+		return
+	}
+
+	action, ok := adminActions[parts[2]]
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, errors.New("unknown action "+parts[2]))
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAdminError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	switch err := action(h.scheduler, info.Id); {
+	case errors.Is(err, ErrTaskNotFound):
+		writeAdminError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrNotTriggered):
+		writeAdminError(w, http.StatusConflict, err)
+	case err != nil:
+		writeAdminError(w, http.StatusInternalServerError, err)
+	case parts[2] == "remove":
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		h.writeTask(w, info.Id)
+	}
+}
+
+// find returns the task with id or name
+func (h *AdminHandler) find(idOrName string) (TaskInfo, bool) {
+	id, err := uuid.Parse(idOrName)
+	for _, info := range h.scheduler.Tasks() {
+		if err == nil && info.Id == id || info.Name != "" && info.Name == idOrName {
+			return info, true
+		}
+	}
+
+	return TaskInfo{}, false
+}
+
+// writeTask writes the task with id and its history
+func (h *AdminHandler) writeTask(w http.ResponseWriter, id uuid.UUID) {
+	info, ok := h.find(id.String())
+	history, err := h.scheduler.History(id)
+	if !ok || err != nil {
+		writeAdminError(w, http.StatusNotFound, ErrTaskNotFound)
+		return
+	}
+
+	task := newAdminTask(info)
+	task.History = history
+	writeAdminJSON(w, http.StatusOK, task)
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,141 @@
+package ticker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func adminRequest(t *testing.T, h http.Handler, method, path string, out interface{}) int {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	if out != nil && w.Code < 300 {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: %v in %s", method, path, err, w.Body)
+		}
+	}
+
+	return w.Code
+}
+
+func TestAdminHandler(t *testing.T) {
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	s := NewScheduler(10).WithClock(clock)
+	s.Start()
+	defer s.Stop()
+
+	runs := make(chan struct{}, 10)
+	go s.Every().Hour().Name("report").DoContext(func(ctx context.Context, task *Task) error {
+		runs <- struct{}{}
+		return errors.New("failed")
+	}, nil)
+	go s.Every().Day().At(2, 0).Do(func(*Task) {}, nil)
+	clock.BlockUntil(1)
+	for len(s.Tasks()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	h := NewAdminHandler(s)
+	var tasks []AdminTask
+	if code := adminRequest(t, h, http.MethodGet, "/tasks", &tasks); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(tasks) != 2 || tasks[0].Name != "report" || !tasks[0].NextRun.Equal(date(t, "2020-03-10 11:00")) || tasks[0].LastRun != nil {
+		t.Fatalf("unexpected tasks %+v", tasks)
+	}
+
+	// run now and wait for the run to be recorded
+	var task AdminTask
+	if code := adminRequest(t, h, http.MethodPost, "/tasks/report/run", &task); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	<-runs
+	for s.Tasks()[0].Runs == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if code := adminRequest(t, h, http.MethodGet, "/tasks/"+tasks[0].Id.String(), &task); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if task.Runs != 1 || task.LastError != "failed" || len(task.History) != 1 || task.History[0].Outcome != OutcomeFailure {
+		t.Fatalf("unexpected task %+v", task)
+	}
+
+	// absent fields are left alone by json, so each response is decoded into a new task
+	task = AdminTask{}
+	if code := adminRequest(t, h, http.MethodPost, "/tasks/report/pause", &task); code != http.StatusOK || !task.Paused || task.NextRun != nil {
+		t.Fatalf("expected a paused task, got %d %+v", code, task)
+	}
+	task = AdminTask{}
+	if code := adminRequest(t, h, http.MethodPost, "/tasks/report/resume", &task); code != http.StatusOK || task.Paused {
+		t.Fatalf("expected a resumed task, got %d %+v", code, task)
+	}
+	if code := adminRequest(t, h, http.MethodPost, "/tasks/report/trigger", nil); code != http.StatusConflict {
+		t.Fatalf("expected 409 for triggering a timed task, got %d", code)
+	}
+	if code := adminRequest(t, h, http.MethodPost, "/tasks/report/remove", nil); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	if code := adminRequest(t, h, http.MethodGet, "/tasks/report", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a removed task, got %d", code)
+	}
+
+	if code := adminRequest(t, h, http.MethodGet, "/tasks/"+tasks[1].Id.String()+"/explode", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown action, got %d", code)
+	}
+	if code := adminRequest(t, h, http.MethodGet, "/tasks/"+tasks[1].Id.String()+"/pause", nil); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET of an action, got %d", code)
+	}
+}
+
+func TestAdminHandler_RemoveRunning(t *testing.T) {
+	// the handler ignores its context, so removing the task would wait for the drain timeout
+	r := startFakeHandler(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		s.WithDrainTimeout(time.Hour)
+		return s.Every().Minute().Name("stuck")
+	}, func(r *fakeRun, ctx context.Context, n int) error {
+		r.block(context.Background(), r.release)
+		return nil
+	})
+	defer r.stop()
+	r.advance(time.Minute)
+	r.expectRuns(1)
+
+	h := NewAdminHandler(r.s)
+	if code := adminRequest(t, h, http.MethodPost, "/tasks/stuck/remove", nil); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	if code := adminRequest(t, h, http.MethodGet, "/tasks/stuck", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a removed task, got %d", code)
+	}
+}
+
+func TestAdminHandler_Auth(t *testing.T) {
+	s := NewScheduler(10)
+	h := NewAdminHandler(s).WithAuth(func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return errors.New("invalid token")
+		}
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	if w.Code != http.StatusUnauthorized || w.Body.String() != "{\"error\":\"invalid token\"}\n" {
+		t.Fatalf("expected 401, got %d %s", w.Code, w.Body)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != "[]\n" {
+		t.Fatalf("expected an empty list, got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,78 @@
+package ticker
+
+import "time"
+
+// Aligned runs an interval of seconds, minutes or hours at the boundaries of the clock instead of counting it from
+// when the task is added, so Every(15).Minutes().Aligned() runs at :00, :15, :30 and :45. Intervals are counted from
+// the start of each minute, hour or day, whichever is the first that is at least as long as the interval, so intervals
+// that don't divide it evenly start over at the next one. Intervals longer than a day are counted from midnight of
+// 2000-01-01 in the task's location, so Every(36).Hours().Aligned() runs every other day at 00:00 and 12:00.
+func (t *TaskConfig) Aligned() *TaskConfig {
+	t.aligned = true
+	return t
+}
+
+// Offset aligns an interval like Aligned and moves its runs by d, so Every().Hour().Offset(7*time.Minute) runs at
+// minute 7 of every hour and Every(10).Seconds().Offset(3*time.Second) runs at :03, :13 and so on.
+func (t *TaskConfig) Offset(d time.Duration) *TaskConfig {
+	t.aligned = true
+	t.offset = d
+	return t
+}
+
+// Jitter delays each scheduled run by a random duration up to d, which spreads the load of replicas that run the same
+// schedule. Runs that are moved out of the task's windows by jitter are missed, so d should be short compared to them.
+func (t *TaskConfig) Jitter(d time.Duration) *TaskConfig {
+	t.jitter = d
+	return t
+}
+
+// alignBase returns the start of the minute, hour or day of now that an aligned interval of period is counted from,
+// and the start of the one after it. For intervals longer than a day, it's the epoch and next is zero.
+func alignBase(now time.Time, period time.Duration, loc *time.Location) (base, next time.Time) {
+	y, m, d := now.Date()
+	switch {
+	case period <= time.Minute:
+		base = wallTime(y, m, d, now.Hour(), now.Minute(), 0, loc)
+		return base, base.Add(time.Minute)
+	case period <= time.Hour:
+		base = wallTime(y, m, d, now.Hour(), 0, 0, loc)
+		return base, base.Add(time.Hour)
+	case period <= unitDays:
+		return wallTime(y, m, d, 0, 0, 0, loc), wallTime(y, m, d+1, 0, 0, 0, loc)
+	default:
+		return wallTime(2000, time.January, 1, 0, 0, 0, loc), time.Time{}
+	}
+}
+
+// nextAligned returns the first run of an aligned interval after now
+func (t *TaskConfig) nextAligned(now time.Time, loc *time.Location) time.Time {
+	period := t.interval * t.unit
+	if period <= 0 {
+		return now
+	}
+
+	offset := t.offset % period
+	if offset < 0 {
+		offset += period
+	}
+
+	base, nextBase := alignBase(now, period, loc)
+	next := base.Add(offset)
+	if !next.After(now) {
+		next = next.Add((now.Sub(next)/period + 1) * period)
+	}
+	if !nextBase.IsZero() && !next.Before(nextBase) {
+		next = nextBase.Add(offset)
+	}
+
+	return next
+}
+
+// jitterDelay returns a random delay for the next run of t. Scheduler must be locked.
+func (s *Scheduler) jitterDelay(t *TaskConfig) time.Duration {
+	if t.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(s.random().Int63n(int64(t.jitter)))
+}
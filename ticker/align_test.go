@@ -0,0 +1,77 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskConfig_NextAligned(t *testing.T) {
+	s := NewScheduler(10)
+	kolkata := loadLocation(t, "Asia/Kolkata")
+	at := func(h, m, sec int) time.Time { return time.Date(2020, 3, 10, h, m, sec, 0, time.UTC) }
+
+	for i, c := range []struct {
+		config   *TaskConfig
+		now      time.Time
+		expected time.Time
+	}{
+		{s.Every(15).Minutes().Aligned(), at(10, 7, 30), at(10, 15, 0)},
+		{s.Every(15).Minutes().Aligned(), at(10, 45, 0), at(11, 0, 0)},
+		{s.Every(10).Seconds().Offset(3 * time.Second), at(10, 0, 4), at(10, 0, 13)},
+		{s.Every(10).Seconds().Offset(3 * time.Second), at(10, 0, 55), at(10, 1, 3)},
+		{s.Every().Hour().Offset(7 * time.Minute), at(10, 6, 0), at(10, 7, 0)},
+		{s.Every().Hour().Offset(7 * time.Minute), at(10, 7, 0), at(11, 7, 0)},
+		{s.Every(7).Minutes().Aligned(), at(10, 57, 0), at(11, 0, 0)},       // starts over every hour
+		{s.Every(5).Hours().Aligned(), at(21, 0, 0), at(24, 0, 0)},          // and every day
+		{s.Every(90).Minutes().Aligned(), at(10, 0, 0), at(10, 30, 0)},      // 00:00, 01:30 and so on
+		{s.Every(6).Hours().Offset(-time.Hour), at(10, 0, 0), at(11, 0, 0)}, // same as 5 hours
+		{s.Every().Hour().Aligned().In(kolkata), at(10, 0, 0), at(10, 30, 0)},
+		{s.Every(36).Hours().Aligned(), at(10, 0, 0), at(36, 0, 0)}, // 00:00 of the 10th is 4916 periods after 2000-01-01
+		{s.Every(36).Hours().Aligned(), at(36, 0, 0), at(72, 0, 0)}, // every 36 hours, not every day
+		{s.Every(48).Hours().Offset(6 * time.Hour), at(10, 0, 0), at(54, 0, 0)},
+	} {
+		if next := c.config.nextRun(time.Time{}, c.now); !next.Equal(c.expected) {
+			t.Errorf("%d: expected %s, got %s", i, c.expected, next)
+		}
+	}
+}
+
+func TestTaskConfig_Aligned(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:07", func(s *Scheduler) *TaskConfig {
+		return s.Every(15).Minutes().Aligned().Between(10, 0, 11, 0)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 10:14", false)
+	r.advanceTo("2020-03-10 10:15", true)
+	r.advanceTo("2020-03-10 10:30", true)
+	r.advanceTo("2020-03-10 10:45", true)
+
+	// windows keep the alignment
+	r.advanceTo("2020-03-10 11:00", false)
+	r.advanceTo("2020-03-11 10:00", true)
+	r.advanceTo("2020-03-11 10:15", true)
+}
+
+func TestTaskConfig_Jitter(t *testing.T) {
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	s := NewScheduler(10).WithClock(clock)
+	s.Start()
+	defer s.Stop()
+
+	task := s.Every().Hour().Aligned().Jitter(time.Minute)
+	go task.Do(func(*Task) {}, nil)
+	clock.BlockUntil(1)
+
+	// runs are delayed, but leases are taken for the time of the schedule
+	s.lock.Lock()
+	at, slot := task.slot.at, task.slot.slot
+	s.lock.Unlock()
+
+	if !slot.Equal(date(t, "2020-03-10 11:00")) || at.Before(slot) || !at.Before(slot.Add(time.Minute)) {
+		t.Fatalf("expected a run up to a minute after 11:00, got %s for %s", at, slot)
+	}
+	if next := s.Tasks()[0].NextRun; !next.Equal(at) {
+		t.Fatalf("expected the next run at %s, got %s", at, next)
+	}
+}
@@ -0,0 +1,181 @@
+package ticker
+
+import "time"
+
+// months and years don't have a fixed length, these units only tell them apart from the others
+const (
+	unitMonths = unitDays * 30
+	unitYears  = unitDays * 365
+)
+
+// lastDay is the monthDay of tasks that run on the last day of the month
+const lastDay = -1
+
+// maxCalendarSteps limits the search for the next run of schedules that match rarely or never, like February 30
+const maxCalendarSteps = 1000
+
+func (t *TaskConfig) Month() *TaskConfig { return t.Months() }
+
+// Months repeats the task every interval months on the day set by OnDay, LastDayOfMonth or OnNthWeekday.
+// By default it runs on the day of the month the task was configured.
+func (t *TaskConfig) Months() *TaskConfig {
+	t.unit = unitMonths
+	return t
+}
+
+func (t *TaskConfig) Year() *TaskConfig { return t.Years() }
+
+// Years repeats the task every interval years in the month set by InMonth, on the day set like Months
+func (t *TaskConfig) Years() *TaskConfig {
+	t.unit = unitYears
+	return t
+}
+
+// On runs the task on each of days every interval weeks. Weeks start on sunday.
+func (t *TaskConfig) On(days ...time.Weekday) *TaskConfig {
+	t.unit = unitWeeks
+	for _, d := range days {
+		t.weekDays |= 1 << uint(d)
+	}
+	return t
+}
+
+// OnDay runs a monthly or yearly task on day of the month. Months that don't have the day are skipped, see LastDayOfMonth.
+func (t *TaskConfig) OnDay(day int) *TaskConfig {
+	t.monthDay = day
+	t.nth = 0
+	return t
+}
+
+// LastDayOfMonth runs a monthly or yearly task on the last day of the month
+func (t *TaskConfig) LastDayOfMonth() *TaskConfig {
+	return t.OnDay(lastDay)
+}
+
+// OnNthWeekday runs a monthly or yearly task on the nth day of the month that is day, like the second tuesday.
+// Negative n counts from the end of the month so -1 is the last one. Months that don't have the day are skipped.
+func (t *TaskConfig) OnNthWeekday(n int, day time.Weekday) *TaskConfig {
+	t.nth = n
+	t.nthDay = day
+	return t
+}
+
+// InMonth sets the month of a yearly task
+func (t *TaskConfig) InMonth(month time.Month) *TaskConfig {
+	t.month = month
+	return t
+}
+
+// normalizeDate normalizes y-m-d like time.Date does, so the day after 03-31 is 04-01
+func normalizeDate(y int, m time.Month, d int) (int, time.Month, int) {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Date()
+}
+
+func (t *TaskConfig) onWeekday(d time.Weekday) bool {
+	if t.weekDays == 0 {
+		return d == t.weekDay
+	}
+	return t.weekDays&(1<<uint(d)) != 0
+}
+
+// nextWeekly returns the next run of a weekly task
+func (t *TaskConfig) nextWeekly(prev, now time.Time, loc *time.Location) (next time.Time) {
+	if prev.IsZero() {
+		// first run is the closest of the days at hour:minute, plus the weeks of the interval
+		for i := 0; i <= 7; i++ {
+			y, m, d := normalizeDate(now.Year(), now.Month(), now.Day()+i)
+			if !t.onWeekday(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday()) {
+				continue
+			}
+			if next = wallTime(y, m, d, t.hour, t.minute, 0, loc); next.After(now) {
+				break
+			}
+		}
+		return wallTime(next.Year(), next.Month(), next.Day()+7*int(t.interval-1), t.hour, t.minute, 0, loc)
+	}
+
+	next = prev.In(loc)
+	for steps := 0; steps == 0 || !next.After(now); steps++ {
+		if steps == maxCalendarSteps {
+			return time.Time{}
+		}
+
+		// the next of the days in the same week or the first of them in the week after the interval
+		y, m, d := next.Date()
+		weekday := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday()
+		found := false
+		for wd := weekday + 1; wd <= time.Saturday; wd++ {
+			if t.onWeekday(wd) {
+				y, m, d = normalizeDate(y, m, d+int(wd-weekday))
+				found = true
+				break
+			}
+		}
+		if !found {
+			y, m, d = normalizeDate(y, m, d-int(weekday)+7*int(t.interval))
+			for wd := time.Sunday; !t.onWeekday(wd) && wd < time.Saturday; wd++ {
+				d++
+			}
+		}
+
+		next = wallTime(y, m, d, t.hour, t.minute, 0, loc)
+	}
+
+	return
+}
+
+// dayOfMonth returns the day of a monthly or yearly task in the month. ok is false if the month doesn't have it.
+func (t *TaskConfig) dayOfMonth(y int, m time.Month) (day int, ok bool) {
+	last := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	switch {
+	case t.nth > 0:
+		first := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).Weekday()
+		day = 1 + (int(t.nthDay)-int(first)+7)%7 + 7*(t.nth-1)
+	case t.nth < 0:
+		lastWeekday := time.Date(y, m, last, 0, 0, 0, 0, time.UTC).Weekday()
+		day = last - (int(lastWeekday)-int(t.nthDay)+7)%7 + 7*(t.nth+1)
+	case t.monthDay == lastDay:
+		day = last
+	default:
+		day = t.monthDay
+	}
+
+	return day, day >= 1 && day <= last
+}
+
+// nextMonthly returns the next run of a monthly or yearly task
+func (t *TaskConfig) nextMonthly(prev, now time.Time, loc *time.Location) time.Time {
+	step := int(t.interval)
+	if t.unit == unitYears {
+		step *= 12
+	}
+
+	var y int
+	var m time.Month
+	if prev.IsZero() {
+		// first run is in the first month that has the day, later ones are the interval apart
+		y, m = now.Year(), now.Month()
+		step = 1
+		if t.unit == unitYears {
+			step = 12
+			if m = t.month; m < now.Month() {
+				y++
+			}
+		}
+	} else {
+		p := prev.In(loc)
+		y, m, _ = normalizeDate(p.Year(), p.Month()+time.Month(step), 1)
+	}
+
+	for steps := 0; steps < maxCalendarSteps; steps++ {
+		if day, ok := t.dayOfMonth(y, m); ok {
+			if next := wallTime(y, m, day, t.hour, t.minute, 0, loc); next.After(now) {
+				return next
+			}
+		}
+		y, m, _ = normalizeDate(y, m+time.Month(step), 1)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,94 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+// expectSchedule checks the first runs of a task configured at now
+func expectSchedule(t *testing.T, name, now string, config func(s *Scheduler) *TaskConfig, expected ...string) {
+	t.Helper()
+
+	s := NewScheduler(1).WithClock(NewFakeClock(date(t, now)))
+	task := config(s)
+
+	var prev time.Time
+	at := date(t, now)
+	for _, e := range expected {
+		next := s.nextAllowed(task, task.nextRun(prev, at))
+		if !next.Equal(date(t, e)) {
+			t.Errorf("%s: expected run at %s, got %s", name, e, next.Format("2006-01-02 15:04"))
+			return
+		}
+		prev, at = next, next
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	// 2020-03-09 is a monday
+	expectSchedule(t, "weekday set", "2020-03-09 12:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().On(time.Monday, time.Wednesday, time.Friday).At(9, 0)
+	}, "2020-03-11 09:00", "2020-03-13 09:00", "2020-03-16 09:00", "2020-03-18 09:00")
+
+	expectSchedule(t, "chained weekdays", "2020-03-09 12:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Monday().Friday().At(9, 0)
+	}, "2020-03-13 09:00", "2020-03-16 09:00", "2020-03-20 09:00")
+
+	expectSchedule(t, "weekday set every two weeks", "2020-03-09 08:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(2).Weeks().On(time.Monday, time.Thursday).At(9, 0)
+	}, "2020-03-16 09:00", "2020-03-19 09:00", "2020-03-30 09:00", "2020-04-02 09:00")
+
+	expectSchedule(t, "monthly", "2020-01-20 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().OnDay(15).At(8, 0)
+	}, "2020-02-15 08:00", "2020-03-15 08:00", "2020-04-15 08:00")
+
+	expectSchedule(t, "every three months", "2020-01-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(3).Months().OnDay(15).At(8, 0)
+	}, "2020-01-15 08:00", "2020-04-15 08:00", "2020-07-15 08:00")
+
+	expectSchedule(t, "default day", "2020-01-20 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().At(8, 0)
+	}, "2020-02-20 08:00", "2020-03-20 08:00")
+
+	expectSchedule(t, "months without the day are skipped", "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().OnDay(31).At(0, 0)
+	}, "2020-01-31 00:00", "2020-03-31 00:00", "2020-05-31 00:00")
+
+	expectSchedule(t, "last day of month", "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().LastDayOfMonth().At(23, 0)
+	}, "2020-01-31 23:00", "2020-02-29 23:00", "2020-03-31 23:00", "2020-04-30 23:00")
+
+	expectSchedule(t, "second tuesday", "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().OnNthWeekday(2, time.Tuesday).At(10, 0)
+	}, "2020-01-14 10:00", "2020-02-11 10:00", "2020-03-10 10:00")
+
+	expectSchedule(t, "last friday", "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().OnNthWeekday(-1, time.Friday).At(17, 0)
+	}, "2020-01-31 17:00", "2020-02-28 17:00", "2020-03-27 17:00")
+
+	expectSchedule(t, "fifth monday", "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().OnNthWeekday(5, time.Monday).At(0, 0)
+	}, "2020-03-30 00:00", "2020-06-29 00:00", "2020-08-31 00:00")
+
+	expectSchedule(t, "yearly", "2020-05-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Year().InMonth(time.March).OnDay(1).At(6, 0)
+	}, "2021-03-01 06:00", "2022-03-01 06:00")
+
+	expectSchedule(t, "leap day", "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Year().InMonth(time.February).OnDay(29).At(0, 0)
+	}, "2020-02-29 00:00", "2024-02-29 00:00")
+}
+
+func TestCalendar_FromTo(t *testing.T) {
+	r := startFake(t, "2020-01-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Month().OnDay(10).At(12, 0).
+			From(date(t, "2020-02-01 00:00")).
+			To(date(t, "2020-04-01 00:00"))
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-01-10 12:00", false)
+	r.advanceTo("2020-02-10 12:00", true)
+	r.advanceTo("2020-03-10 12:00", true)
+	r.expectDone()
+}
@@ -0,0 +1,170 @@
+package ticker
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of a scheduler
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+	// NewTimerAt makes a timer that fires at the absolute time at, so it isn't late if the clock moves while it's
+	// being armed
+	NewTimerAt(at time.Time) Timer
+}
+
+// Timer is the Clock's version of time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+	ResetAt(at time.Time) bool
+}
+
+// RealClock returns a Clock backed by the time package
+func RealClock() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+func (realClock) NewTimerAt(at time.Time) Timer {
+	return realTimer{time.NewTimer(time.Until(at))}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+func (t realTimer) ResetAt(at time.Time) bool  { return t.t.Reset(time.Until(at)) }
+
+// resetTimerAt stops t, drains its channel if it has fired and resets it to fire at at
+func resetTimerAt(t Timer, at time.Time) {
+	stopTimer(t)
+	t.ResetAt(at)
+}
+
+func stopTimer(t Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C():
+		default:
+		}
+	}
+}
+
+// FakeClock is a Clock that only moves when it's advanced manually. It's meant for testing schedules.
+type FakeClock struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers map[*fakeTimer]struct{} // armed timers
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{
+		now:    now,
+		timers: make(map[*fakeTimer]struct{}),
+	}
+	c.cond = sync.NewCond(&c.lock)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.NewTimerAt(c.Now().Add(d))
+}
+
+func (c *FakeClock) NewTimerAt(at time.Time) Timer {
+	t := &fakeTimer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+	t.ResetAt(at)
+	return t
+}
+
+// Advance moves the clock forward by d and fires the timers that are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+func (c *FakeClock) fire() {
+	for t := range c.timers {
+		if c.now.Before(t.when) {
+			continue
+		}
+
+		delete(c.timers, t)
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until there are at least n armed timers on the clock
+func (c *FakeClock) BlockUntil(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	c     chan time.Time
+	when  time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	_, armed := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	t.clock.cond.Broadcast()
+
+	return armed
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return t.ResetAt(t.clock.Now().Add(d))
+}
+
+func (t *fakeTimer) ResetAt(at time.Time) bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, armed := c.timers[t]
+	t.when = at
+	c.timers[t] = struct{}{}
+	c.fire()
+
+	return armed
+}
@@ -0,0 +1,259 @@
+package ticker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of allowed values.
+type cronSchedule struct {
+	expr string
+
+	second, minute, hour, dom, month, dow uint64
+
+	// dom and dow are matched with OR when both of them are restricted, like standard cron
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	cronSeconds = cronField{name: "second", min: 0, max: 59}
+	cronMinutes = cronField{name: "minute", min: 0, max: 59}
+	cronHours   = cronField{name: "hour", min: 0, max: 23}
+	cronDom     = cronField{name: "day of month", min: 1, max: 31}
+	cronMonths  = cronField{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is also accepted as sunday and folded into 0 after parsing
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard 5 field cron expression (minute hour dom month dow),
+// a 6 field expression with a leading seconds field or one of the @ macros.
+func parseCron(expr string) (c *cronSchedule, err error) {
+	expr = strings.TrimSpace(expr)
+	original := expr
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("cron: unknown macro %q", expr)
+		}
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, got %d in %q", len(fields), expr)
+	}
+
+	c = &cronSchedule{expr: original}
+	for i, f := range []struct {
+		out   *uint64
+		field cronField
+	}{
+		{&c.second, cronSeconds},
+		{&c.minute, cronMinutes},
+		{&c.hour, cronHours},
+		{&c.dom, cronDom},
+		{&c.month, cronMonths},
+		{&c.dow, cronDow},
+	} {
+		if *f.out, err = f.field.parse(fields[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domStar = isCronStar(fields[3])
+	c.dowStar = isCronStar(fields[5])
+
+	return
+}
+
+func isCronStar(s string) bool {
+	return strings.HasPrefix(s, "*") || strings.HasPrefix(s, "?")
+}
+
+func (f cronField) parse(s string) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		var b uint64
+		if b, err = f.parseRange(part); err != nil {
+			return
+		}
+		bits |= b
+	}
+
+	return
+}
+
+// parseRange parses one of *, n, n-m with an optional /step
+func (f cronField) parseRange(s string) (bits uint64, err error) {
+	rangePart, step := s, uint(1)
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		rangePart = s[:i]
+		n, convErr := strconv.ParseUint(s[i+1:], 10, 8)
+		if convErr != nil || n == 0 {
+			return 0, fmt.Errorf("cron: invalid step %q in %s field %q", s[i+1:], f.name, s)
+		}
+		step = uint(n)
+	}
+
+	var from, to uint
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		from, to = f.min, f.max
+		if f.max == 7 {
+			// don't count sunday twice
+			to = 6
+		}
+	case strings.IndexByte(rangePart, '-') >= 0:
+		i := strings.IndexByte(rangePart, '-')
+		if from, err = f.value(rangePart[:i]); err != nil {
+			return
+		}
+		if to, err = f.value(rangePart[i+1:]); err != nil {
+			return
+		}
+		if from > to {
+			return 0, fmt.Errorf("cron: invalid range %q in %s field", rangePart, f.name)
+		}
+	default:
+		if from, err = f.value(rangePart); err != nil {
+			return
+		}
+		to = from
+		if step > 1 {
+			// n/step means n-max/step
+			to = f.max
+		}
+	}
+
+	for v := from; v <= to; v += step {
+		bits |= 1 << v
+	}
+
+	return
+}
+
+func (f cronField) value(s string) (uint, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(n) < f.min || uint(n) > f.max {
+		return 0, fmt.Errorf("cron: invalid value %q in %s field, expected %d-%d", s, f.name, f.min, f.max)
+	}
+
+	return uint(n), nil
+}
+
+const cronAllHours = 1<<24 - 1
+
+func hasBit(bits uint64, v int) bool { return bits&(1<<uint(v)) != 0 }
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := hasBit(c.dom, t.Day())
+	dowMatch := hasBit(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next returns the first time after t that matches the schedule or zero time if there's none in the next 5 years.
+func (c *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for !hasBit(c.month, int(t.Month())) {
+		t = wallTime(t.Year(), t.Month()+1, 1, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !c.dayMatches(t) {
+		t = wallTime(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for !hasBit(c.hour, t.Hour()) {
+		prev := t.Hour()
+		t = wallTime(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, loc)
+		// hours that are skipped by a daylight saving time gap run after it
+		for h := prev + 1; h < t.Hour(); h++ {
+			if hasBit(c.hour, h) {
+				return wallTime(t.Year(), t.Month(), t.Day(), h, firstBit(c.minute), firstBit(c.second), loc)
+			}
+		}
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for !hasBit(c.minute, t.Minute()) {
+		t = t.Truncate(time.Minute).Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for !hasBit(c.second, t.Second()) {
+		t = t.Truncate(time.Second).Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	// times that repeat when clocks are turned back run once, unless the task runs every hour anyway
+	if c.hour != cronAllHours && repeatedWallTime(t) {
+		t = t.Add(time.Second)
+		goto wrap
+	}
+
+	return t
+}
+
+func firstBit(bits uint64) int {
+	for v := 0; v < 64; v++ {
+		if hasBit(bits, v) {
+			return v
+		}
+	}
+	return 0
+}
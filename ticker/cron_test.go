@@ -0,0 +1,100 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		invalid bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/10 9-17 * * 1-5"},
+		{expr: "15 2 1,15 * *"},
+		{expr: "0 30 4 * * *"},
+		{expr: "0 0 * jan-mar MON,wed,Fri"},
+		{expr: "0 0 * * 7"},
+		{expr: "5/15 * * * ?"},
+		{expr: "@daily"},
+		{expr: "@HOURLY"},
+		{expr: "", invalid: true},
+		{expr: "* * * *", invalid: true},
+		{expr: "* * * * * * *", invalid: true},
+		{expr: "60 * * * *", invalid: true},
+		{expr: "* 24 * * *", invalid: true},
+		{expr: "* * 0 * *", invalid: true},
+		{expr: "* * * 13 *", invalid: true},
+		{expr: "* * * * 8", invalid: true},
+		{expr: "*/0 * * * *", invalid: true},
+		{expr: "10-5 * * * *", invalid: true},
+		{expr: "a * * * *", invalid: true},
+		{expr: "@fortnightly", invalid: true},
+	}
+
+	for _, test := range tests {
+		_, err := parseCron(test.expr)
+		if test.invalid && err == nil {
+			t.Errorf("%q: expected an error", test.expr)
+		} else if !test.invalid && err != nil {
+			t.Errorf("%q: unexpected error %v", test.expr, err)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		expr, from, next string
+	}{
+		{"* * * * *", "2020-03-10 10:00:00", "2020-03-10 10:01:00"},
+		{"* * * * *", "2020-03-10 10:00:59", "2020-03-10 10:01:00"},
+		{"* * * * * *", "2020-03-10 10:00:00", "2020-03-10 10:00:01"},
+		{"*/10 9-17 * * 1-5", "2020-03-10 10:05:00", "2020-03-10 10:10:00"},
+		{"*/10 9-17 * * 1-5", "2020-03-10 17:55:00", "2020-03-11 09:00:00"},
+		// friday evening to monday morning
+		{"*/10 9-17 * * 1-5", "2020-03-13 17:50:00", "2020-03-16 09:00:00"},
+		{"15 2 1,15 * *", "2020-03-01 02:15:00", "2020-03-15 02:15:00"},
+		{"15 2 1,15 * *", "2020-03-20 00:00:00", "2020-04-01 02:15:00"},
+		{"0 0 29 2 *", "2021-01-01 00:00:00", "2024-02-29 00:00:00"},
+		{"0 0 31 * *", "2020-04-01 00:00:00", "2020-05-31 00:00:00"},
+		// dom and dow are ORed when both are restricted
+		{"0 0 13 * 5", "2020-03-02 00:00:00", "2020-03-06 00:00:00"},
+		{"0 0 * * 7", "2020-03-10 00:00:00", "2020-03-15 00:00:00"},
+		{"0 0 * dec *", "2020-03-10 00:00:00", "2020-12-01 00:00:00"},
+		{"30 */20 * * * *", "2020-03-10 10:59:00", "2020-03-10 11:00:30"},
+		{"@daily", "2020-12-31 13:00:00", "2021-01-01 00:00:00"},
+		{"@hourly", "2020-03-10 10:00:00", "2020-03-10 11:00:00"},
+		{"@weekly", "2020-03-10 10:00:00", "2020-03-15 00:00:00"},
+		{"@monthly", "2020-03-10 10:00:00", "2020-04-01 00:00:00"},
+		{"@yearly", "2020-03-10 10:00:00", "2021-01-01 00:00:00"},
+		{"0 0 30 2 *", "2020-03-10 10:00:00", ""},
+	}
+
+	for _, test := range tests {
+		c, err := parseCron(test.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", test.expr, err)
+		}
+
+		next := c.next(date(test.from))
+		if test.next == "" {
+			if !next.IsZero() {
+				t.Errorf("%q from %s: expected no next run, got %s", test.expr, test.from, next)
+			}
+			continue
+		}
+
+		if expected := date(test.next); !next.Equal(expected) {
+			t.Errorf("%q from %s: expected %s, got %s", test.expr, test.from, expected, next)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package ticker
+
+import "time"
+
+type entryKind int
+
+const (
+	entrySlot    entryKind = iota // run of the task's schedule
+	entryRetry                    // retry of a failed run
+	entryManual                   // run requested by Scheduler.RunNow
+	entryTrigger                  // run requested by a trigger
+)
+
+// entry is a pending run of a task
+type entry struct {
+	task    *TaskConfig
+	at      time.Time
+	kind    entryKind
+	attempt int
+	slot    time.Time // time of the schedule that the run is for, before jitter
+	index   int       // index in the heap or -1
+}
+
+// entryHeap is a min-heap of pending runs ordered by their time. It implements heap.Interface.
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
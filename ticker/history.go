@@ -0,0 +1,221 @@
+package ticker
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultHistorySize is the number of runs that are kept for each task unless the scheduler or the task sets another
+const DefaultHistorySize = 100
+
+// Outcome is the result of a run
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomePanic   Outcome = "panic"
+)
+
+// RunRecord is a finished run of a task
+type RunRecord struct {
+	TaskId   uuid.UUID     `json:"task_id"`
+	Name     string        `json:"name,omitempty"`
+	Attempt  int           `json:"attempt"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	Outcome  Outcome       `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// runRecord returns the record of the finished run of e
+func (e Event) runRecord() RunRecord {
+	r := RunRecord{
+		TaskId:   e.Id,
+		Name:     e.Name,
+		Attempt:  e.Attempt,
+		Start:    e.Start,
+		End:      e.End,
+		Duration: e.Duration,
+		Outcome:  OutcomeSuccess,
+	}
+
+	switch {
+	case e.Panic != nil:
+		r.Outcome = OutcomePanic
+		r.Error = e.Err.Error()
+	case e.Err != nil:
+		r.Outcome = OutcomeFailure
+		r.Error = e.Err.Error()
+	}
+
+	return r
+}
+
+// HistorySink persists the runs of tasks
+type HistorySink interface {
+	Record(r RunRecord) error
+}
+
+// WithHistory sets the number of runs kept in memory for each task. Negative keeps none.
+func (s *Scheduler) WithHistory(size int) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.historySize = size
+	return s
+}
+
+// WithHistorySink sends each finished run to sink. Its errors are reported to the function set by OnStoreError.
+func (s *Scheduler) WithHistorySink(sink HistorySink) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.historySink = sink
+	return s
+}
+
+// History sets the number of runs of the task that are kept in memory. Negative keeps none and zero uses the
+// scheduler's size.
+func (t *TaskConfig) History(size int) *TaskConfig {
+	t.historySize = size
+	return t
+}
+
+// record adds r to the history of t. Scheduler must be locked.
+func (s *Scheduler) record(t *TaskConfig, r RunRecord) {
+	size := t.historySize
+	if size == 0 {
+		size = s.historySize
+	}
+	if size <= 0 {
+		return
+	}
+
+	if len(t.history) != size && t.historyNext != 0 {
+		// the size changed after the ring was full, so it's put in order before it grows or shrinks
+		ordered := make([]RunRecord, 0, len(t.history))
+		ordered = append(ordered, t.history[t.historyNext:]...)
+		t.history = append(ordered, t.history[:t.historyNext]...)
+		t.historyNext = 0
+	}
+	if len(t.history) < size {
+		t.history = append(t.history, r)
+		return
+	}
+
+	// history is a ring once it's full and historyNext is its oldest record
+	if len(t.history) > size {
+		t.history = t.history[len(t.history)-size:]
+	}
+	t.history[t.historyNext] = r
+	t.historyNext = (t.historyNext + 1) % size
+}
+
+// History returns the runs of a task that are kept in memory, from the oldest to the newest
+func (s *Scheduler) History(id uuid.UUID) (History, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, err := s.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	h := make(History, 0, len(t.history))
+	h = append(h, t.history[t.historyNext:]...)
+	h = append(h, t.history[:t.historyNext]...)
+	return h, nil
+}
+
+// History is a list of runs from the oldest to the newest
+type History []RunRecord
+
+// SuccessRate returns the ratio of successful runs, from 0 to 1. It's zero if there are no runs.
+func (h History) SuccessRate() float64 {
+	if len(h) == 0 {
+		return 0
+	}
+
+	n := 0
+	for _, r := range h {
+		if r.Outcome == OutcomeSuccess {
+			n++
+		}
+	}
+
+	return float64(n) / float64(len(h))
+}
+
+// Percentile returns the duration that p percent of the runs took at most, like 50 for the median
+func (h History) Percentile(p float64) time.Duration {
+	if len(h) == 0 {
+		return 0
+	}
+
+	durations := make([]time.Duration, len(h))
+	for i, r := range h {
+		durations[i] = r.Duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	// nearest rank
+	i := int(math.Ceil(p/100*float64(len(durations)))) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(durations) {
+		i = len(durations) - 1
+	}
+
+	return durations[i]
+}
+
+// Failures returns up to n of the newest failed and panicked runs, from the newest to the oldest
+func (h History) Failures(n int) (list []RunRecord) {
+	for i := len(h) - 1; i >= 0 && len(list) < n; i-- {
+		if h[i].Outcome != OutcomeSuccess {
+			list = append(list, h[i])
+		}
+	}
+
+	return
+}
+
+// FileHistorySink is a HistorySink that appends runs to a file as JSON lines
+type FileHistorySink struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewFileHistorySink(path string) *FileHistorySink {
+	return &FileHistorySink{path: path}
+}
+
+func (f *FileHistorySink) Record(r RunRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
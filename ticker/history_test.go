@@ -0,0 +1,193 @@
+package ticker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memorySink struct {
+	lock    sync.Mutex
+	records []RunRecord
+}
+
+func (m *memorySink) Record(r RunRecord) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.records = append(m.records, r)
+	return nil
+}
+
+func TestHistory(t *testing.T) {
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	sink := &memorySink{}
+	s := NewScheduler(10).WithClock(clock).WithHistory(3).WithHistorySink(sink)
+	s.Start()
+	defer s.Stop()
+
+	// runs take 1 to 5 seconds and the even ones fail
+	runs := make(chan int, 10)
+	n := 0
+	task := s.Every().Minute()
+	go task.DoContext(func(ctx context.Context, task *Task) error {
+		n++
+		clock.Advance(time.Duration(n) * time.Second)
+		runs <- n
+		if n%2 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	}, nil)
+
+	for i := 1; i <= 5; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		<-runs
+	}
+	clock.BlockUntil(1)
+
+	h, err := s.History(task.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(h))
+	}
+	for i, r := range h {
+		if r.Duration != time.Duration(i+3)*time.Second || r.TaskId != task.id || !r.End.Equal(r.Start.Add(r.Duration)) {
+			t.Fatalf("unexpected run %d %+v", i, r)
+		}
+	}
+	if h[1].Outcome != OutcomeFailure || h[1].Error != "failed" || h[2].Outcome != OutcomeSuccess {
+		t.Fatalf("unexpected outcomes %+v", h)
+	}
+
+	// the sink is called after the scheduler handles the run
+	deadline := time.Now().Add(time.Second)
+	for {
+		sink.lock.Lock()
+		recorded := len(sink.records)
+		sink.lock.Unlock()
+
+		if recorded == 5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 5 runs in the sink, got %d", recorded)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHistory_Resize(t *testing.T) {
+	s := NewScheduler(10).WithHistory(3)
+	task := s.Every()
+	task.id = uuid.New()
+	s.tasks[task.id] = task
+
+	attempts := func() (list []int) {
+		h, _ := s.History(task.id)
+		for _, r := range h {
+			list = append(list, r.Attempt)
+		}
+		return
+	}
+
+	// the ring is full and starts in the middle
+	for i := 1; i <= 5; i++ {
+		s.record(task, RunRecord{Attempt: i})
+	}
+
+	s.WithHistory(5)
+	s.record(task, RunRecord{Attempt: 6})
+	if list := attempts(); !reflect.DeepEqual(list, []int{3, 4, 5, 6}) {
+		t.Fatalf("expected the grown history in order, got %v", list)
+	}
+
+	for i := 7; i <= 8; i++ {
+		s.record(task, RunRecord{Attempt: i})
+	}
+	s.WithHistory(2)
+	s.record(task, RunRecord{Attempt: 9})
+	if list := attempts(); !reflect.DeepEqual(list, []int{8, 9}) {
+		t.Fatalf("expected the newest runs after shrinking, got %v", list)
+	}
+}
+
+func TestHistory_Stats(t *testing.T) {
+	var h History
+	for i := 1; i <= 20; i++ {
+		r := RunRecord{Attempt: i, Duration: time.Duration(i) * time.Second, Outcome: OutcomeSuccess}
+		if i%5 == 0 {
+			r.Outcome = OutcomeFailure
+		}
+		h = append(h, r)
+	}
+
+	if p := h.Percentile(50); p != 10*time.Second {
+		t.Errorf("expected p50 of 10s, got %s", p)
+	}
+	if p := h.Percentile(95); p != 19*time.Second {
+		t.Errorf("expected p95 of 19s, got %s", p)
+	}
+	if p := h.Percentile(100); p != 20*time.Second {
+		t.Errorf("expected p100 of 20s, got %s", p)
+	}
+	if r := h.SuccessRate(); r != 0.8 {
+		t.Errorf("expected success rate of 0.8, got %v", r)
+	}
+
+	failures := h.Failures(3)
+	if len(failures) != 3 || failures[0].Attempt != 20 || failures[1].Attempt != 15 || failures[2].Attempt != 10 {
+		t.Errorf("unexpected failures %+v", failures)
+	}
+
+	var empty History
+	if empty.Percentile(50) != 0 || empty.SuccessRate() != 0 || len(empty.Failures(1)) != 0 {
+		t.Error("expected zero stats of an empty history")
+	}
+}
+
+func TestFileHistorySink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ticker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := NewFileHistorySink(filepath.Join(dir, "history.jsonl"))
+	for i := 1; i <= 2; i++ {
+		if err = sink.Record(RunRecord{Name: "report", Attempt: i, Outcome: OutcomeSuccess}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []RunRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r RunRecord
+		if err = json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 || records[1].Attempt != 2 || records[1].Name != "report" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+}
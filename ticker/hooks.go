@@ -0,0 +1,173 @@
+package ticker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kiyoptr/su/log"
+)
+
+// Event describes a run of a task for hooks
+type Event struct {
+	Id        uuid.UUID
+	Name      string
+	Attempt   int
+	Scheduled time.Time // time the run was due
+	Start     time.Time // zero for missed runs
+	End       time.Time // zero for missed runs and OnStart
+	Duration  time.Duration
+	Err       error       // error of failed and panicked runs
+	Panic     interface{} // recovered value of panicked runs
+	Missed    int         // number of missed runs
+	Reason    string      // why runs were missed
+}
+
+// reasons of missed runs
+const (
+	MissedOverlap  = "overlap"  // the task was running and its overlap policy skipped the run
+	MissedBlackout = "blackout" // the run was due in a blackout or out of the task's windows
+	MissedDowntime = "downtime" // the runs were due while the program was down and the missed run policy skipped them
+	MissedLease    = "lease"    // another scheduler holds the lease of the task or already ran the slot
+)
+
+// Hooks are called on the events of runs. Nil hooks are ignored.
+// They're called outside of the scheduler's lock, so they can use the scheduler, but they delay the run that calls them.
+type Hooks struct {
+	OnStart   func(e Event)
+	OnSuccess func(e Event)
+	OnError   func(e Event)
+	OnPanic   func(e Event)
+	OnMissed  func(e Event)
+}
+
+// WithHooks adds hooks that are called for the runs of all tasks
+func (s *Scheduler) WithHooks(h Hooks) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.hooks = append(s.hooks, h)
+	return s
+}
+
+// Hooks adds hooks that are called for the runs of the task, after the hooks of the scheduler
+func (t *TaskConfig) Hooks(h Hooks) *TaskConfig {
+	t.hooks = append(t.hooks, h)
+	return t
+}
+
+type hookKind int
+
+const (
+	hookStart hookKind = iota
+	hookSuccess
+	hookError
+	hookPanic
+	hookMissed
+)
+
+// pendingEvent is an event that happened while the scheduler was locked
+type pendingEvent struct {
+	hooks []Hooks
+	kind  hookKind
+	event Event
+}
+
+// hooksOf returns the hooks of the scheduler and t. Scheduler must be locked.
+func (s *Scheduler) hooksOf(t *TaskConfig) []Hooks {
+	if len(s.hooks) == 0 {
+		return t.hooks
+	}
+	if len(t.hooks) == 0 {
+		return s.hooks
+	}
+	return append(append(make([]Hooks, 0, len(s.hooks)+len(t.hooks)), s.hooks...), t.hooks...)
+}
+
+func fire(hooks []Hooks, kind hookKind, e Event) {
+	for _, h := range hooks {
+		var f func(Event)
+		switch kind {
+		case hookStart:
+			f = h.OnStart
+		case hookSuccess:
+			f = h.OnSuccess
+		case hookError:
+			f = h.OnError
+		case hookPanic:
+			f = h.OnPanic
+		case hookMissed:
+			f = h.OnMissed
+		}
+
+		if f != nil {
+			f(e)
+		}
+	}
+}
+
+// missedLocked queues the OnMissed event of n runs of t. Scheduler must be locked.
+func (s *Scheduler) missedLocked(t *TaskConfig, scheduled time.Time, n int, reason string) {
+	hooks := s.hooksOf(t)
+	if len(hooks) == 0 || n == 0 {
+		return
+	}
+
+	s.events = append(s.events, pendingEvent{
+		hooks: hooks,
+		kind:  hookMissed,
+		event: Event{Id: t.id, Name: t.name, Scheduled: scheduled, Missed: n, Reason: reason},
+	})
+}
+
+// flushEvents writes the records and calls the hooks of the events that were queued while the scheduler was locked.
+// Scheduler must not be locked.
+func (s *Scheduler) flushEvents() {
+	s.flushWrites()
+
+	s.lock.Lock()
+	events := s.events
+	s.events = nil
+	s.lock.Unlock()
+
+	for _, e := range events {
+		fire(e.hooks, e.kind, e.event)
+	}
+}
+
+// LogHooks returns hooks that write the events of runs to l, tagged with the id and name of their task.
+// Starts are written in debug mode, successes in info mode, missed runs in warning mode and the rest in error mode.
+// Errors of failed runs are written in one line with their stack and fields, see log.ErrorFields.
+func LogHooks(l *log.Logger) Hooks {
+	// tags of a write are set by separate calls, keep writes of parallel runs apart
+	var lock sync.Mutex
+	write := func(mode log.Mode, e Event, message string, fields ...log.Field) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		l.Mode(mode).
+			Fields(log.String("task_id", e.Id.String()), log.String("task_name", e.Name)).
+			Fields(fields...).
+			Print(message)
+	}
+
+	return Hooks{
+		OnStart: func(e Event) {
+			write(log.Debug, e, "task started", log.Int("attempt", e.Attempt))
+		},
+		OnSuccess: func(e Event) {
+			write(log.Info, e, "task succeeded", log.Int("attempt", e.Attempt), log.Duration("duration", e.Duration))
+		},
+		OnError: func(e Event) {
+			fields := []log.Field{log.Int("attempt", e.Attempt), log.Duration("duration", e.Duration)}
+			write(log.Error, e, "task failed", append(fields, log.ErrorFields(e.Err)...)...)
+		},
+		OnPanic: func(e Event) {
+			write(log.Error, e, "task panicked", log.Int("attempt", e.Attempt), log.Duration("duration", e.Duration),
+				log.Any("panic", e.Panic))
+		},
+		OnMissed: func(e Event) {
+			write(log.Warning, e, "task missed runs", log.Int("missed", e.Missed), log.String("reason", e.Reason))
+		},
+	}
+}
@@ -0,0 +1,150 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kiyoptr/su/log"
+)
+
+// eventLog records the events of hooks
+type eventLog struct {
+	lock   sync.Mutex
+	events []string
+	last   Event
+}
+
+func (l *eventLog) hooks(prefix string) Hooks {
+	record := func(kind string) func(Event) {
+		return func(e Event) {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			l.events = append(l.events, prefix+kind)
+			l.last = e
+		}
+	}
+
+	return Hooks{
+		OnStart:   record("start"),
+		OnSuccess: record("success"),
+		OnError:   record("error"),
+		OnPanic:   record("panic"),
+		OnMissed:  record("missed"),
+	}
+}
+
+func (l *eventLog) expect(t *testing.T, events ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.lock.Lock()
+		got := strings.Join(l.events, ",")
+		l.lock.Unlock()
+
+		if got == strings.Join(events, ",") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected events %v, got %s", events, got)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	events := &eventLog{}
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	s := NewScheduler(10).WithClock(clock).WithHooks(events.hooks("s."))
+	s.Start()
+	defer s.Stop()
+
+	fail := errors.New("failed")
+	runs := 0
+	go s.Every(10).Minutes().Name("report").Hooks(events.hooks("t.")).DoContext(func(ctx context.Context, task *Task) error {
+		runs++
+		clock.Advance(time.Minute)
+		if runs == 2 {
+			return fail
+		}
+		return nil
+	}, nil)
+	clock.BlockUntil(1)
+
+	clock.Advance(10 * time.Minute)
+	events.expect(t, "s.start", "t.start", "s.success", "t.success")
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Minute)
+	events.expect(t, "s.start", "t.start", "s.success", "t.success", "s.start", "t.start", "s.error", "t.error")
+
+	events.lock.Lock()
+	e := events.last
+	events.lock.Unlock()
+	if e.Name != "report" || e.Err != fail || e.Attempt != 1 || e.Duration != time.Minute ||
+		!e.Scheduled.Equal(date(t, "2020-03-10 10:21")) || !e.Start.Equal(e.Scheduled) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+func TestHooks_Missed(t *testing.T) {
+	events := &eventLog{}
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Seconds().Overlap(OverlapSkip).Hooks(Hooks{OnMissed: events.hooks("").OnMissed})
+	}, blockingHandler)
+	defer r.stop()
+
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+	r.advance(10 * time.Second)
+	events.expect(t, "missed")
+
+	events.lock.Lock()
+	e := events.last
+	events.lock.Unlock()
+	if e.Missed != 1 || e.Reason != MissedOverlap || !e.Start.IsZero() {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+type captureAdapter struct {
+	lock  sync.Mutex
+	lines []string
+}
+
+func (c *captureAdapter) Write(message string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lines = append(c.lines, message)
+}
+
+func (c *captureAdapter) Close() error { return nil }
+
+func TestLogHooks(t *testing.T) {
+	c := &captureAdapter{}
+	l, err := log.New().WithAdapters(c).Name("ticker").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := LogHooks(l)
+	h.OnError(Event{Name: "report", Attempt: 2, Duration: 1500 * time.Millisecond, Err: errors.New("failed")})
+	h.OnMissed(Event{Name: "report", Missed: 3, Reason: MissedDowntime})
+
+	expected := []string{
+		"[name=ticker] [mode=error] [task_id=00000000-0000-0000-0000-000000000000] [task_name=report] [attempt=2] [duration=1.5s] [error=failed] [message=task failed]",
+		"[name=ticker] [mode=warning] [task_id=00000000-0000-0000-0000-000000000000] [task_name=report] [missed=3] [reason=downtime] [message=task missed runs]",
+	}
+	if len(c.lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %v", len(expected), c.lines)
+	}
+	for i := range expected {
+		if c.lines[i] != expected[i] {
+			t.Errorf("line %d:\nexpected %s\ngot      %s", i, expected[i], c.lines[i])
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultLeaseTTL is the ttl of leases when WithLocker is given one that's too short to be renewed
+const DefaultLeaseTTL = time.Minute
+
+// ErrLeaseLost is reported when a running task's lease couldn't be renewed because another owner took it over
+var ErrLeaseLost = errors.New("ticker: lease lost")
+
+// Lease is the right of one scheduler to run a slot of a named task until it expires
+type Lease struct {
+	Name    string
+	Owner   string
+	Slot    time.Time // scheduled time of the run
+	Expires time.Time
+}
+
+// Locker keeps one lease for each named task so that only one of several schedulers runs each slot of the task.
+// It's usually backed by a database that all of them share, like sqlstore.Locker.
+type Locker interface {
+	// Acquire takes the lease of l.Name for l.Slot if nobody holds it, its holder finished an earlier slot or its
+	// holder's lease expired before now. It returns false if the slot already ran or another owner holds the lease.
+	Acquire(l Lease, now time.Time) (bool, error)
+	// Renew extends the lease to l.Expires. It returns false if the owner doesn't hold the lease anymore.
+	Renew(l Lease) (bool, error)
+	// Release marks the slot of the lease as done, so no other owner runs it again
+	Release(l Lease) error
+}
+
+// WithLocker makes the scheduler take a lease from locker before each scheduled run of its named tasks, so when
+// several instances of a program schedule the same tasks, each slot runs on only one of them. Leases last for ttl
+// and are renewed while the handler runs. A lease whose holder crashed is taken over after it expires. A ttl under a
+// second is replaced by DefaultLeaseTTL, since the lease would expire before it's renewed.
+//
+// Slots are matched by their scheduled time before jitter, so the tasks should have schedules that don't depend on
+// when they were added, like cron expressions, days at a time or aligned intervals. Unnamed tasks, retries and runs
+// requested by RunNow aren't locked.
+// Runs that can't get the lease are reported to OnMissed hooks with MissedLease.
+func (s *Scheduler) WithLocker(locker Locker, ttl time.Duration) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if ttl < time.Second {
+		ttl = DefaultLeaseTTL
+	}
+
+	host, _ := os.Hostname()
+	s.locker = locker
+	s.lockTTL = ttl
+	s.owner = host + "/" + uuid.New().String()
+	return s
+}
+
+// acquire takes the lease of the run of e if it needs one. It returns a nil lease if the run isn't locked and false
+// if it can't run. Scheduler must not be locked.
+func (s *Scheduler) acquire(locker Locker, lease Lease, ttl time.Duration, e *entry) (*Lease, bool) {
+	if locker == nil || lease.Name == "" || e.kind != entrySlot {
+		return nil, true
+	}
+
+	now := s.clock.Now()
+	lease.Slot = e.slot
+	lease.Expires = now.Add(ttl)
+	ok, err := locker.Acquire(lease, now)
+	if err != nil {
+		// running it without the lease could run it twice
+		s.storeError(err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	return &lease, true
+}
+
+// heartbeat renews lease every third of its ttl until the returned function is called. If the lease is lost, the
+// handler is cancelled.
+func (s *Scheduler) heartbeat(locker Locker, lease *Lease, ttl time.Duration, cancel context.CancelFunc) (stop func()) {
+	if lease == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		timer := s.clock.NewTimer(ttl / 3)
+		defer stopTimer(timer)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C():
+			}
+
+			renewed := *lease
+			renewed.Expires = s.clock.Now().Add(ttl)
+			ok, err := locker.Renew(renewed)
+			if err != nil {
+				// the lease may still be valid, so it's renewed again
+				s.storeError(err)
+			} else if !ok {
+				s.storeError(ErrLeaseLost)
+				cancel()
+				return
+			}
+			timer.Reset(ttl / 3)
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
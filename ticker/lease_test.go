@@ -0,0 +1,151 @@
+package ticker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryLocker holds leases in memory and loses them when lost is set
+type memoryLocker struct {
+	lock   sync.Mutex
+	leases map[string]Lease
+	done   map[string]bool
+	renews int
+	lost   bool
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{leases: make(map[string]Lease), done: make(map[string]bool)}
+}
+
+func (m *memoryLocker) Acquire(l Lease, now time.Time) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	held, ok := m.leases[l.Name]
+	if ok && (m.done[l.Name] && !held.Slot.Before(l.Slot) || !m.done[l.Name] && held.Expires.After(now)) {
+		return false, nil
+	}
+
+	m.leases[l.Name] = l
+	m.done[l.Name] = false
+	return true, nil
+}
+
+func (m *memoryLocker) Renew(l Lease) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.renews++
+	if m.lost || m.leases[l.Name].Owner != l.Owner {
+		return false, nil
+	}
+
+	held := m.leases[l.Name]
+	held.Expires = l.Expires
+	m.leases[l.Name] = held
+	return true, nil
+}
+
+func (m *memoryLocker) Release(l Lease) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.leases[l.Name].Owner == l.Owner {
+		m.done[l.Name] = true
+	}
+	return nil
+}
+
+func TestLease_Heartbeat(t *testing.T) {
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	locker := newMemoryLocker()
+	lost := make(chan error, 1)
+	s := NewScheduler(10).WithClock(clock).WithLocker(locker, 30*time.Second).OnStoreError(func(err error) {
+		lost <- err
+	})
+	s.Start()
+	defer s.Stop()
+
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{}, 1)
+	go s.Every().Hour().Name("report").DoContext(func(ctx context.Context, task *Task) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		cancelled <- struct{}{}
+		return ctx.Err()
+	}, nil)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-started
+
+	// the lease is renewed every 10 seconds while the handler runs. The next run is scheduled after it returns, so
+	// the heartbeat is the only timer.
+	for i := 1; i <= 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Second)
+		for {
+			locker.lock.Lock()
+			renews := locker.renews
+			locker.lock.Unlock()
+			if renews == i {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// the handler is cancelled once the lease is lost
+	locker.lock.Lock()
+	locker.lost = true
+	locker.lock.Unlock()
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the handler to be cancelled")
+	}
+	if err := <-lost; err != ErrLeaseLost {
+		t.Fatalf("expected ErrLeaseLost, got %v", err)
+	}
+}
+
+func TestLease_Unnamed(t *testing.T) {
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	locker := newMemoryLocker()
+	s := NewScheduler(10).WithClock(clock).WithLocker(locker, time.Minute)
+	s.Start()
+	defer s.Stop()
+
+	// unnamed tasks don't take leases
+	runs := make(chan struct{}, 1)
+	go s.Every().Hour().Do(func(*Task) { runs <- struct{}{} }, nil)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected a run")
+	}
+	locker.lock.Lock()
+	defer locker.lock.Unlock()
+	if len(locker.leases) != 0 {
+		t.Fatalf("unexpected leases %+v", locker.leases)
+	}
+}
+
+func TestLease_ShortTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{-time.Second, 0, 2, time.Millisecond} {
+		if s := NewScheduler(10).WithLocker(newMemoryLocker(), ttl); s.lockTTL != DefaultLeaseTTL {
+			t.Errorf("%s: expected the default ttl, got %s", ttl, s.lockTTL)
+		}
+	}
+	if s := NewScheduler(10).WithLocker(newMemoryLocker(), time.Second); s.lockTTL != time.Second {
+		t.Errorf("expected a ttl of a second, got %s", s.lockTTL)
+	}
+}
@@ -0,0 +1,52 @@
+package ticker
+
+// OverlapPolicy decides what happens when a run of a task is due while the previous one is still running
+type OverlapPolicy int
+
+const (
+	// OverlapDelay schedules the next run after the running one returns. It's the default policy.
+	OverlapDelay OverlapPolicy = iota
+	// OverlapSkip skips runs that are due while the task is running
+	OverlapSkip
+	// OverlapQueue keeps one due run and starts it as soon as the running one returns. Other due runs are skipped.
+	OverlapQueue
+	// OverlapParallel runs up to a number of runs at the same time and skips the rest. See TaskConfig.Parallel
+	OverlapParallel
+)
+
+// Overlap sets the overlap policy of the task. Skipped runs are counted in Task.Skipped
+func (t *TaskConfig) Overlap(p OverlapPolicy) *TaskConfig {
+	t.overlap = p
+	return t
+}
+
+// Parallel allows up to n runs of the task at the same time. Each run gets its own copy of Task.
+func (t *TaskConfig) Parallel(n int) *TaskConfig {
+	t.overlap = OverlapParallel
+	t.parallel = n
+	return t
+}
+
+// limit returns the number of runs that can run at the same time
+func (t *TaskConfig) limit() int {
+	if t.overlap == OverlapParallel && t.parallel > 1 {
+		return t.parallel
+	}
+	return 1
+}
+
+// overlapped handles a due run that can't start because the task is already running. It returns false if the run is
+// skipped. Scheduler must be locked.
+func (t *TaskConfig) overlapped(e *entry) bool {
+	switch {
+	case t.overlap == OverlapDelay:
+		t.queued = append(t.queued, e)
+	case t.overlap == OverlapQueue && len(t.queued) == 0:
+		t.queued = append(t.queued, e)
+	default:
+		t.task.Skipped++
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,93 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverlap_Delay(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig { return s.Every(10).Seconds() }, blockingHandler)
+	defer r.stop()
+
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+	r.advance(25 * time.Second)
+	r.expectRuns(0)
+
+	// next run is 10 seconds after the previous one returns
+	r.releaseOne()
+	r.advance(9 * time.Second)
+	r.expectRuns(0)
+	r.advance(time.Second)
+	r.expectRuns(1)
+	r.expectSkipped(0)
+}
+
+func TestOverlap_Skip(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Seconds().Overlap(OverlapSkip)
+	}, blockingHandler)
+	defer r.stop()
+
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+	r.advance(10 * time.Second)
+	r.advance(10 * time.Second)
+	r.expectRuns(0)
+	r.expectSkipped(2)
+
+	r.releaseOne()
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+}
+
+func TestOverlap_Queue(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Seconds().Overlap(OverlapQueue)
+	}, blockingHandler)
+	defer r.stop()
+
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+	r.advance(10 * time.Second)
+	r.advance(10 * time.Second)
+	r.expectSkipped(1)
+
+	// queued run starts right away
+	r.release <- struct{}{}
+	r.expectRuns(1)
+}
+
+func TestOverlap_Parallel(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Seconds().Parallel(2)
+	}, blockingHandler)
+	defer r.stop()
+
+	r.advance(10 * time.Second)
+	r.advance(10 * time.Second)
+	r.expectRuns(2)
+	r.advance(10 * time.Second)
+	r.expectRuns(0)
+	r.expectSkipped(1)
+
+	r.releaseOne()
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+}
+
+func TestScheduler_Workers(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		s.WithWorkers(1)
+		return s.Every(10).Seconds().Parallel(5)
+	}, blockingHandler)
+	defer r.stop()
+
+	r.advance(10 * time.Second)
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+
+	// second run waits for the only worker
+	r.release <- struct{}{}
+	r.expectRuns(1)
+}
@@ -0,0 +1,85 @@
+package ticker
+
+import (
+	goerrors "errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/kiyoptr/su/errors"
+)
+
+// PanicError is the inner error of the *errors.Error that a panicking run returns
+type PanicError struct {
+	Value interface{} // value that was passed to panic
+	Stack []byte      // stack of the panicking goroutine
+}
+
+func (p *PanicError) Error() string { return fmt.Sprintf("panic: %v", p.Value) }
+
+// Unwrap returns the value of the panic if it's an error
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// newPanicError makes an error of a recovered panic. It must be called by the function that recovered it.
+func newPanicError(value interface{}) error {
+	return errors.NewError(panicSource(), goerrors.New("task panicked"), &PanicError{
+		Value: value,
+		Stack: debug.Stack(),
+	})
+}
+
+// panicSource returns the file and line that panicked, which is the first frame after the runtime's panic functions
+func panicSource() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	inPanic := false
+	for {
+		f, more := frames.Next()
+		if strings.HasPrefix(f.Function, "runtime.") {
+			inPanic = true
+		} else if inPanic {
+			return fmt.Sprintf("%v:%v", f.File, f.Line)
+		}
+
+		if !more {
+			return "<no source>"
+		}
+	}
+}
+
+// MaxPanics disables the task after its handler panics n times. A disabled task is paused and can be resumed by
+// Scheduler.Resume. Zero uses the scheduler's limit.
+func (t *TaskConfig) MaxPanics(n int) *TaskConfig {
+	t.maxPanics = n
+	return t
+}
+
+// WithMaxPanics sets the number of panics that disable a task if the task doesn't set its own limit.
+// Zero, the default, never disables tasks.
+func (s *Scheduler) WithMaxPanics(n int) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.maxPanics = n
+	return s
+}
+
+// panicked counts a panic of t and disables it if it panicked too many times. Scheduler must be locked.
+func (s *Scheduler) panicked(t *TaskConfig) {
+	t.task.Panics++
+	t.panics++
+
+	max := t.maxPanics
+	if max == 0 {
+		max = s.maxPanics
+	}
+	if max > 0 && t.panics >= max {
+		t.paused = true
+		s.unqueueAll(t)
+	}
+}
@@ -0,0 +1,94 @@
+package ticker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kiyoptr/su/errors"
+)
+
+// panicOn returns a handler that panics on the runs in panics
+func panicOn(panics ...int) fakeHandler {
+	return func(r *fakeRun, ctx context.Context, n int) error {
+		for _, p := range panics {
+			if p == n {
+				panic(fmt.Sprintf("run %d", n))
+			}
+		}
+		return nil
+	}
+}
+
+func TestPanic_Recovered(t *testing.T) {
+	events := &eventLog{}
+	r := startFakeHandler(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Seconds().Hooks(events.hooks(""))
+	}, panicOn(1))
+	defer r.s.Stop()
+	start := r.clock.Now()
+
+	r.advance(10 * time.Second)
+	r.expectRunAt(start.Add(10 * time.Second))
+	events.expect(t, "start", "panic")
+
+	r.s.lock.Lock()
+	err := r.config.task.Err
+	r.s.lock.Unlock()
+
+	var e *errors.Error
+	var p *PanicError
+	if !errors.As(err, &e) || !errors.As(err, &p) {
+		t.Fatalf("expected *errors.Error with a PanicError, got %#v", err)
+	}
+	if p.Value != "run 1" || !strings.Contains(string(p.Stack), "panicOn") {
+		t.Fatalf("unexpected panic error %v\n%s", p, p.Stack)
+	}
+	if !strings.Contains(e.Source, "panic_test.go") {
+		t.Fatalf("expected the source to be the panic, got %s", e.Source)
+	}
+
+	// the schedule keeps running
+	r.advance(10 * time.Second)
+	r.expectRunAt(start.Add(20 * time.Second))
+	events.expect(t, "start", "panic", "start", "success")
+
+	info := r.s.Tasks()[0]
+	if info.Panics != 1 || info.Failures != 1 || info.Runs != 2 || info.Paused {
+		t.Fatalf("unexpected snapshot %+v", info)
+	}
+}
+
+func TestPanic_MaxPanics(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		s.WithMaxPanics(5)
+		return s.Every(10).Seconds().MaxPanics(2)
+	}, panicOn(1, 3, 4))
+	defer r.s.Stop()
+
+	for i := 0; i < 3; i++ {
+		r.advance(10 * time.Second)
+		r.expectRuns(1)
+	}
+
+	// second panic disables the task
+	if info := r.s.Tasks()[0]; !info.Paused || info.Panics != 2 {
+		t.Fatalf("unexpected snapshot %+v", info)
+	}
+	r.advance(time.Minute)
+	r.expectNoRun()
+
+	// resuming counts panics again
+	if err := r.s.Resume(r.config.id); err != nil {
+		t.Fatal(err)
+	}
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+	r.advance(10 * time.Second)
+	r.expectRuns(1)
+	if info := r.s.Tasks()[0]; info.Paused || info.Panics != 3 {
+		t.Fatalf("unexpected snapshot %+v", info)
+	}
+}
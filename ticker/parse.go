@@ -0,0 +1,640 @@
+package ticker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError is returned by Parse for a schedule it doesn't understand. Pos is the byte offset of Token in Text, or
+// the length of Text if the schedule ended too early.
+type ParseError struct {
+	Text  string
+	Token string
+	Pos   int
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("ticker: %s at the end of %q", e.Msg, e.Text)
+	}
+	return fmt.Sprintf("ticker: %s at %q (position %d) in %q", e.Msg, e.Token, e.Pos, e.Text)
+}
+
+var parseUnits = map[string]func(*TaskConfig) *TaskConfig{
+	"second": (*TaskConfig).Seconds,
+	"minute": (*TaskConfig).Minutes,
+	"hour":   (*TaskConfig).Hours,
+	"day":    (*TaskConfig).Days,
+	"week":   (*TaskConfig).Weeks,
+	"month":  (*TaskConfig).Months,
+	"year":   (*TaskConfig).Years,
+}
+
+var unitNames = map[time.Duration]string{
+	unitSeconds: "second",
+	unitMinutes: "minute",
+	unitHours:   "hour",
+	unitDays:    "day",
+	unitWeeks:   "week",
+	unitMonths:  "month",
+	unitYears:   "year",
+}
+
+var ordinalWords = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1}
+
+// Parse begins configuring a task from a schedule in words, like the fluent API would:
+//
+//	every 3 hours from 09:00 to 17:00         Every(3).Hours().Between(9, 0, 17, 0)
+//	every monday and thursday at 15:30        Every().On(time.Monday, time.Thursday).At(15, 30)
+//	every 2 weeks on friday at 08:00          Every(2).Weeks().On(time.Friday).At(8, 0)
+//	every month on the 2nd tuesday at 09:00   Every().Months().OnNthWeekday(2, time.Tuesday).At(9, 0)
+//	every year in march on the 15th at 06:00  Every().Years().InMonth(time.March).OnDay(15).At(6, 0)
+//	every hour at minute 7 jitter 30s         Every().Hour().Offset(7 * time.Minute).Jitter(30 * time.Second)
+//
+// A schedule starts with "every", an optional interval and a unit or weekdays, followed by any of "at HH:MM",
+// "at <offset>" which aligns intervals of hours and shorter like Offset (":SS" for intervals up to a minute, ":MM" up
+// to an hour, "HH:MM" for longer ones, or "minute N" or "second N" for any of them), "jitter <duration>",
+// "on <weekdays>" for weeks, "on the <day>" for months and years, "in <month>" for years, "in <time zone>",
+// "from HH:MM to HH:MM", "except <blackouts>" and "or on trigger". "on trigger" parses like OnTrigger and
+// "cron <expression>" like Cron.
+// Errors are *ParseError and point at the word that couldn't be parsed.
+func (s *Scheduler) Parse(text string) (*TaskConfig, error) {
+	p := &parser{text: text, tokens: tokenize(text)}
+
+	if tok, ok := p.accept("cron"); ok {
+		t, err := s.Cron(strings.TrimSpace(text[tok.pos+len(tok.text):]))
+		if err != nil {
+			return nil, &ParseError{Text: text, Token: tok.text, Pos: tok.pos, Msg: err.Error()}
+		}
+		return t, nil
+	}
+
+	if _, ok := p.accept("on"); ok {
+		if _, err := p.expect("trigger", "expected \"trigger\""); err != nil {
+			return nil, err
+		}
+		t := s.OnTrigger()
+		return t, p.clauses(t)
+	}
+
+	if _, err := p.expect("every", "expected \"every\" or \"on trigger\""); err != nil {
+		return nil, err
+	}
+
+	interval := 1
+	if tok, ok := p.peek(); ok {
+		if n, err := strconv.Atoi(tok.text); err == nil {
+			if n < 1 {
+				return nil, p.errorAt(tok, "expected a positive interval")
+			}
+			interval = n
+			p.i++
+		}
+	}
+	t := s.Every(interval)
+
+	tok, err := p.expect("", "expected a unit or weekdays")
+	if err != nil {
+		return nil, err
+	}
+	if unit, ok := parseUnits[strings.TrimSuffix(tok.lower(), "s")]; ok {
+		unit(t)
+	} else if _, ok = parseWeekday(tok.lower()); ok && interval == 1 {
+		p.i--
+		days, err := p.weekdays()
+		if err != nil {
+			return nil, err
+		}
+		t.On(days...)
+	} else {
+		return nil, p.errorAt(tok, "expected a unit or weekdays")
+	}
+
+	return t, p.clauses(t)
+}
+
+// Parse begins configuring a task from a schedule in words on the default scheduler. See Scheduler.Parse.
+func Parse(text string) (*TaskConfig, error) { return Default().Parse(text) }
+
+type token struct {
+	text string
+	pos  int
+}
+
+func (t token) lower() string { return strings.ToLower(t.text) }
+
+// tokenize splits text into words. Commas are words of their own.
+func tokenize(text string) (tokens []token) {
+	start := -1
+	for i, r := range text + " " {
+		if r == ' ' || r == '\t' || r == '\n' || r == ',' {
+			if start >= 0 {
+				tokens = append(tokens, token{text: text[start:i], pos: start})
+				start = -1
+			}
+			if r == ',' {
+				tokens = append(tokens, token{text: ",", pos: i})
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return
+}
+
+type parser struct {
+	text   string
+	tokens []token
+	i      int
+}
+
+func (p *parser) errorAt(tok token, msg string) error {
+	return &ParseError{Text: p.text, Token: tok.text, Pos: tok.pos, Msg: msg}
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.i >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.i], true
+}
+
+// accept consumes the next token if it's word
+func (p *parser) accept(word string) (token, bool) {
+	tok, ok := p.peek()
+	if !ok || tok.lower() != word {
+		return token{}, false
+	}
+	p.i++
+	return tok, true
+}
+
+// expect consumes the next token, which must be word unless word is empty
+func (p *parser) expect(word, msg string) (token, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return tok, &ParseError{Text: p.text, Pos: len(p.text), Msg: msg}
+	}
+	if word != "" && tok.lower() != word {
+		return tok, p.errorAt(tok, msg)
+	}
+	p.i++
+	return tok, nil
+}
+
+// clauses parses the clauses after the unit
+func (p *parser) clauses(t *TaskConfig) error {
+	seen := make(map[string]bool)
+	for {
+		tok, ok := p.peek()
+		if !ok {
+			return nil
+		}
+		p.i++
+
+		word := tok.lower()
+		if seen[word] && word != "from" && word != "in" {
+			return p.errorAt(tok, "repeated "+word)
+		}
+		seen[word] = true
+
+		var err error
+		switch word {
+		case "at":
+			err = p.at(t, tok)
+		case "on":
+			err = p.on(t, tok)
+		case "in":
+			err = p.in(t)
+		case "from":
+			err = p.between(t)
+		case "except":
+			err = p.except(t)
+		case "or":
+			err = p.orOnTrigger(t)
+		case "jitter":
+			err = p.jitter(t)
+		default:
+			err = p.errorAt(tok, "expected \"at\", \"on\", \"in\", \"from\", \"except\", \"jitter\" or \"or\"")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) at(t *TaskConfig, at token) error {
+	if t.unit == 0 {
+		return p.errorAt(at, "\"at\" needs a schedule")
+	}
+	if t.unit < unitDays {
+		return p.offset(t)
+	}
+
+	tok, err := p.expect("", "expected a time like 15:30")
+	if err != nil {
+		return err
+	}
+	hour, minute, ok := parseClock(tok.text)
+	if !ok {
+		return p.errorAt(tok, "expected a time like 15:30")
+	}
+
+	t.At(hour, minute)
+	return nil
+}
+
+// offset parses the offset of an aligned interval
+func (p *parser) offset(t *TaskConfig) error {
+	tok, err := p.expect("", "expected an offset like :05, minute 5 or second 5")
+	if err != nil {
+		return err
+	}
+
+	period := t.interval * t.unit
+	var offset time.Duration
+	switch word := tok.lower(); {
+	case word == "minute" || word == "second":
+		n, err := p.expect("", "expected a number")
+		if err != nil {
+			return err
+		}
+		tok = n
+		v, err := strconv.Atoi(n.text)
+		if err != nil || v < 0 {
+			return p.errorAt(n, "expected a number")
+		}
+		offset = time.Duration(v) * time.Minute
+		if word == "second" {
+			offset = time.Duration(v) * time.Second
+		}
+	case strings.HasPrefix(word, ":"):
+		v, err := strconv.Atoi(word[1:])
+		if err != nil || len(word) != 3 || v > 59 {
+			return p.errorAt(tok, "expected an offset like :05")
+		}
+		offset = time.Duration(v) * time.Minute
+		if period <= time.Minute {
+			offset = time.Duration(v) * time.Second
+		}
+	default:
+		hour, minute, ok := parseClock(word)
+		if !ok || period <= time.Hour {
+			return p.errorAt(tok, "expected an offset like :05, minute 5 or second 5")
+		}
+		offset = time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	}
+	if offset >= period {
+		return p.errorAt(tok, "expected an offset shorter than the interval")
+	}
+
+	t.Offset(offset)
+	return nil
+}
+
+func (p *parser) jitter(t *TaskConfig) error {
+	tok, err := p.expect("", "expected a duration like 30s")
+	if err != nil {
+		return err
+	}
+
+	d, err := time.ParseDuration(tok.text)
+	if err != nil || d <= 0 {
+		return p.errorAt(tok, "expected a duration like 30s")
+	}
+
+	t.Jitter(d)
+	return nil
+}
+
+func (p *parser) on(t *TaskConfig, on token) error {
+	switch {
+	case t.unit == unitWeeks:
+		days, err := p.weekdays()
+		if err != nil {
+			return err
+		}
+		t.weekDays = 0
+		t.On(days...)
+		return nil
+	case t.unit == unitMonths || t.unit == unitYears:
+	default:
+		return p.errorAt(on, "\"on\" needs a schedule of weeks, months or years")
+	}
+
+	p.accept("the")
+	tok, err := p.expect("", "expected a day like the 15th, the last day or the 2nd tuesday")
+	if err != nil {
+		return err
+	}
+
+	n, ok := ordinalWords[tok.lower()]
+	if !ok {
+		if n, ok = parseOrdinal(tok.lower()); !ok || n < 1 || n > 31 {
+			return p.errorAt(tok, "expected a day like the 15th, the last day or the 2nd tuesday")
+		}
+	}
+
+	next, ok := p.peek()
+	if ok {
+		if day, isDay := parseWeekday(next.lower()); isDay {
+			p.i++
+			if n > 5 {
+				return p.errorAt(tok, "expected a weekday from the first to the fifth")
+			}
+			t.OnNthWeekday(n, day)
+			return nil
+		}
+		if next.lower() == "day" && n == -1 {
+			p.i++
+			t.LastDayOfMonth()
+			return nil
+		}
+	}
+
+	if n == -1 {
+		return p.errorAt(tok, "expected \"last day\" or the last weekday")
+	}
+	t.OnDay(n)
+	return nil
+}
+
+func (p *parser) in(t *TaskConfig) error {
+	tok, err := p.expect("", "expected a month or a time zone")
+	if err != nil {
+		return err
+	}
+
+	if month, ok := parseMonth(tok.lower()); ok {
+		if t.unit != unitYears {
+			return p.errorAt(tok, "a month needs a schedule of years")
+		}
+		t.InMonth(month)
+		return nil
+	}
+
+	loc, err := time.LoadLocation(tok.text)
+	if err != nil {
+		return p.errorAt(tok, "expected a month or a time zone")
+	}
+	t.In(loc)
+	return nil
+}
+
+func (p *parser) between(t *TaskConfig) error {
+	var times [2]token
+	for i, word := range []string{"", "to"} {
+		if word != "" {
+			if _, err := p.expect(word, "expected \"to\""); err != nil {
+				return err
+			}
+		}
+
+		tok, err := p.expect("", "expected a time like 09:00")
+		if err != nil {
+			return err
+		}
+		times[i] = tok
+	}
+
+	fromHour, fromMinute, ok := parseClock(times[0].text)
+	if !ok {
+		return p.errorAt(times[0], "expected a time like 09:00")
+	}
+	toHour, toMinute, ok := parseClock(times[1].text)
+	if !ok {
+		return p.errorAt(times[1], "expected a time like 17:00")
+	}
+
+	t.Between(fromHour, fromMinute, toHour, toMinute)
+	return nil
+}
+
+func (p *parser) except(t *TaskConfig) error {
+	for {
+		tok, err := p.expect("", "expected the name of a blackout")
+		if err != nil {
+			return err
+		}
+		t.Except(tok.text)
+
+		if _, ok := p.accept(","); !ok {
+			if _, ok = p.accept("and"); !ok {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) orOnTrigger(t *TaskConfig) error {
+	for _, word := range []string{"on", "trigger"} {
+		if _, err := p.expect(word, "expected \"or on trigger\""); err != nil {
+			return err
+		}
+	}
+
+	t.OrOnTrigger()
+	return nil
+}
+
+// weekdays parses a list of weekdays like "monday, wednesday and friday"
+func (p *parser) weekdays() (days []time.Weekday, err error) {
+	for {
+		tok, err := p.expect("", "expected a weekday")
+		if err != nil {
+			return nil, err
+		}
+		day, ok := parseWeekday(tok.lower())
+		if !ok {
+			return nil, p.errorAt(tok, "expected a weekday")
+		}
+		days = append(days, day)
+
+		if _, ok = p.accept(","); !ok {
+			if _, ok = p.accept("and"); !ok {
+				return days, nil
+			}
+		}
+	}
+}
+
+// parseClock parses a time of day like 9:30 or 09:30
+func parseClock(s string) (hour, minute int, ok bool) {
+	i := strings.IndexByte(s, ':')
+	if i < 1 || len(s)-i != 3 {
+		return 0, 0, false
+	}
+
+	hour, err := strconv.Atoi(s[:i])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, false
+	}
+	minute, err = strconv.Atoi(s[i+1:])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, 0, false
+	}
+
+	return hour, minute, true
+}
+
+// parseOrdinal parses ordinals like 1st, 2nd, 3rd and 15th
+func parseOrdinal(s string) (int, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-2])
+	if err != nil || ordinal(n) != s {
+		return 0, false
+	}
+	return n, true
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// parseWeekday parses the name of a weekday or its first three letters
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseMonth parses the name of a month or its first three letters
+func parseMonth(s string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if s == name || s == name[:3] {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// String returns the schedule of the task in the words that Parse reads. Settings that Parse doesn't have words for,
+// like From, To and Once, are left out. A task made by Every without a unit has no words either, so it's rendered as
+// "every" and its interval, which Parse rejects.
+func (t *TaskConfig) String() string {
+	if t.cron != nil {
+		return "cron " + t.cron.expr
+	}
+
+	sb := strings.Builder{}
+	if !t.timed() {
+		sb.WriteString("on trigger")
+		t.writeClauses(&sb)
+		return sb.String()
+	}
+
+	sb.WriteString("every")
+
+	var days []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if t.unit == unitWeeks && t.onWeekday(d) {
+			days = append(days, strings.ToLower(d.String()))
+		}
+	}
+
+	switch {
+	case t.unit == unitWeeks && t.interval == 1:
+		sb.WriteString(" " + joinWords(days))
+	case t.unit == 0:
+		fmt.Fprintf(&sb, " %d", t.interval)
+	case t.interval == 1:
+		sb.WriteString(" " + unitNames[t.unit])
+	default:
+		fmt.Fprintf(&sb, " %d %ss", t.interval, unitNames[t.unit])
+	}
+
+	switch t.unit {
+	case unitWeeks:
+		if t.interval != 1 {
+			sb.WriteString(" on " + joinWords(days))
+		}
+	case unitYears:
+		sb.WriteString(" in " + strings.ToLower(t.month.String()))
+		fallthrough
+	case unitMonths:
+		switch {
+		case t.nth == -1:
+			sb.WriteString(" on the last " + strings.ToLower(t.nthDay.String()))
+		case t.nth != 0:
+			sb.WriteString(" on the " + ordinal(t.nth) + " " + strings.ToLower(t.nthDay.String()))
+		case t.monthDay == lastDay:
+			sb.WriteString(" on the last day")
+		default:
+			sb.WriteString(" on the " + ordinal(t.monthDay))
+		}
+	}
+
+	if t.unit >= unitDays {
+		fmt.Fprintf(&sb, " at %02d:%02d", t.hour, t.minute)
+	} else if t.aligned {
+		sb.WriteString(" at " + t.offsetString())
+	}
+	t.writeClauses(&sb)
+	if t.triggered {
+		sb.WriteString(" or on trigger")
+	}
+
+	return sb.String()
+}
+
+// writeClauses writes the clauses that any schedule can have
+func (t *TaskConfig) writeClauses(sb *strings.Builder) {
+	if t.loc != nil {
+		sb.WriteString(" in " + t.loc.String())
+	}
+	for _, w := range t.windows {
+		fmt.Fprintf(sb, " from %02d:%02d to %02d:%02d", w.from/60, w.from%60, w.to/60, w.to%60)
+	}
+	if len(t.blackouts) > 0 {
+		sb.WriteString(" except " + strings.Join(t.blackouts, ", "))
+	}
+	if t.jitter > 0 {
+		sb.WriteString(" jitter " + t.jitter.String())
+	}
+}
+
+// offsetString writes the offset of an aligned interval like Parse reads it
+func (t *TaskConfig) offsetString() string {
+	period := t.interval * t.unit
+	switch {
+	case period <= time.Minute && t.offset%time.Second == 0 && t.offset < time.Minute:
+		return fmt.Sprintf(":%02d", t.offset/time.Second)
+	case t.offset%time.Minute != 0:
+		return fmt.Sprintf("second %d", t.offset/time.Second)
+	case period <= time.Hour && t.offset < time.Hour:
+		return fmt.Sprintf(":%02d", t.offset/time.Minute)
+	case period > time.Hour && t.offset < 24*time.Hour:
+		return fmt.Sprintf("%02d:%02d", t.offset/time.Hour, t.offset%time.Hour/time.Minute)
+	default:
+		return fmt.Sprintf("minute %d", t.offset/time.Minute)
+	}
+}
+
+// joinWords joins words like "monday, wednesday and friday"
+func joinWords(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
@@ -0,0 +1,114 @@
+package ticker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	s := NewScheduler(10).WithClock(NewFakeClock(date(t, "2020-03-10 10:00")))
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	for _, c := range []struct {
+		text     string
+		expected *TaskConfig
+		str      string // String of the parsed config if it's not text
+	}{
+		{"every 3 hours from 09:00 to 17:00", s.Every(3).Hours().Between(9, 0, 17, 0), ""},
+		{"every monday at 15:30", s.Every().Monday().At(15, 30), ""},
+		{"every 2 weeks on friday at 08:00", s.Every(2).Weeks().Friday().At(8, 0), ""},
+		{"Every Monday, Wed and friday at 9:05", s.Every().On(time.Monday, time.Wednesday, time.Friday).At(9, 5), "every monday, wednesday and friday at 09:05"},
+		{"every 10 seconds", s.Every(10).Seconds(), ""},
+		{"every minute", s.Every().Minute(), ""},
+		{"every 2 days at 06:00 in Europe/Berlin", s.Every(2).Days().At(6, 0).In(berlin), ""},
+		{"every month on the 15th at 02:00", s.Every().Month().OnDay(15).At(2, 0), ""},
+		{"every month on the last day at 23:00", s.Every().Month().LastDayOfMonth().At(23, 0), ""},
+		{"every 3 months on the 2nd tuesday at 09:00", s.Every(3).Months().OnNthWeekday(2, time.Tuesday).At(9, 0), ""},
+		{"every month on the last friday at 17:00", s.Every().Month().OnNthWeekday(-1, time.Friday).At(17, 0), ""},
+		{"every year in march on the first at 00:00", s.Every().Year().InMonth(time.March).OnDay(1).At(0, 0), "every year in march on the 1st at 00:00"},
+		{"every 10 seconds at :03", s.Every(10).Seconds().Offset(3 * time.Second), ""},
+		{"every hour at minute 7", s.Every().Hour().Offset(7 * time.Minute), "every hour at :07"},
+		{"every 15 minutes at :00 jitter 30s", s.Every(15).Minutes().Aligned().Jitter(30 * time.Second), ""},
+		{"every 6 hours at 01:30", s.Every(6).Hours().Offset(90 * time.Minute), ""},
+		{"on trigger from 09:00 to 17:00", s.OnTrigger().Between(9, 0, 17, 0), ""},
+		{"every 6 hours or on trigger", s.Every(6).Hours().OrOnTrigger(), ""},
+		{"every hour from 22:00 to 02:00 from 12:00 to 13:00 except holidays, maintenance", s.Every().Hour().Between(22, 0, 2, 0).Between(12, 0, 13, 0).Except("holidays", "maintenance"), ""},
+	} {
+		config, err := s.Parse(c.text)
+		if err != nil {
+			t.Errorf("%s: %v", c.text, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(config.schedule(), c.expected.schedule()) {
+			t.Errorf("%s: expected %+v, got %+v", c.text, c.expected.schedule(), config.schedule())
+		}
+
+		str := c.str
+		if str == "" {
+			str = c.text
+		}
+		if config.String() != str {
+			t.Errorf("%s: expected String %q, got %q", c.text, str, config.String())
+		}
+	}
+}
+
+func TestParse_Cron(t *testing.T) {
+	config, err := Parse("cron 0 2 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.cron == nil || config.String() != "cron 0 2 * * 1-5" {
+		t.Fatalf("unexpected config %s", config)
+	}
+}
+
+func TestTaskConfig_StringWithoutUnit(t *testing.T) {
+	if str := NewScheduler(10).Every(3).String(); str != "every 3" {
+		t.Fatalf("expected \"every 3\", got %q", str)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, c := range []struct {
+		text  string
+		token string
+		pos   int
+	}{
+		{"each monday", "each", 0},
+		{"every 3 hourz", "hourz", 8},
+		{"every 0 days", "0", 6},
+		{"every 2 monday", "monday", 8},
+		{"every monday at 25:00", "25:00", 16},
+		{"every hour at 10:00", "10:00", 14},
+		{"every minute at :5", ":5", 16},
+		{"every hour at minute 70", "70", 21},
+		{"every 30 seconds at :45", ":45", 20},
+		{"every 2 hours at 02:00", "02:00", 17},
+		{"every day jitter soon", "soon", 17},
+		{"every day on monday", "on", 10},
+		{"every month on the 32nd", "32nd", 19},
+		{"every month on the last", "last", 19},
+		{"every month in march", "march", 15},
+		{"every day in Nowhere/City", "Nowhere/City", 13},
+		{"every day from 09:00 until 17:00", "until", 21},
+		{"every day at 09:00 at 10:00", "at", 19},
+		{"every week on monday and", "", 24},
+		{"every", "", 5},
+		{"on time", "time", 3},
+		{"every hour or trigger", "trigger", 14},
+		{"cron 0 2 * *", "cron", 0},
+	} {
+		_, err := Parse(c.text)
+		e, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%s: expected a *ParseError, got %v", c.text, err)
+			continue
+		}
+		if e.Token != c.token || e.Pos != c.pos {
+			t.Errorf("%s: expected the error at %q (%d), got %v", c.text, c.token, c.pos, e)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package ticker
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTaskNotFound is returned by the methods of Scheduler that act on a task which isn't scheduled
+var ErrTaskNotFound = errors.New("ticker: task not found")
+
+// TaskInfo is a snapshot of the state of a scheduled task
+type TaskInfo struct {
+	Id        uuid.UUID
+	Name      string
+	Schedule  Schedule
+	NextRun   time.Time // zero if the next run isn't scheduled, like while the task is paused or running
+	LastRun   time.Time // zero if the task hasn't run yet
+	LastError error
+	Runs      int // number of finished runs
+	Failures  int
+	Panics    int
+	Skipped   int
+	Running   int  // number of running handlers
+	Paused    bool // paused by Scheduler.Pause or disabled after panicking too many times
+}
+
+// Tasks returns the snapshots of all scheduled tasks ordered by their next run.
+// Tasks without a next run come last.
+func (s *Scheduler) Tasks() []TaskInfo {
+	s.lock.Lock()
+	list := make([]TaskInfo, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		list = append(list, t.info())
+	}
+	s.lock.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].NextRun, list[j].NextRun
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return list[i].Id.String() < list[j].Id.String()
+	})
+
+	return list
+}
+
+// info returns a snapshot of t. Scheduler must be locked.
+func (t *TaskConfig) info() (i TaskInfo) {
+	i = TaskInfo{
+		Id:        t.id,
+		Name:      t.name,
+		Schedule:  t.schedule(),
+		LastError: t.task.Err,
+		Runs:      t.task.Runs,
+		Failures:  t.task.Failures,
+		Panics:    t.task.Panics,
+		Skipped:   t.task.Skipped,
+		Running:   t.running,
+		Paused:    t.paused,
+	}
+	if t.slot != nil {
+		i.NextRun = t.slot.at
+	}
+	if e := t.trigger; e != nil && e.index >= 0 && (i.NextRun.IsZero() || e.at.Before(i.NextRun)) {
+		i.NextRun = e.at
+	}
+	if t.task.Runs > 0 {
+		i.LastRun = t.lastRun
+	}
+
+	return
+}
+
+// lookup returns the scheduled task with id. Scheduler must be locked.
+func (s *Scheduler) lookup(id uuid.UUID) (*TaskConfig, error) {
+	t, ok := s.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return t, nil
+}
+
+// Pause stops scheduling runs of a task until it's resumed. Running handlers aren't cancelled.
+func (s *Scheduler) Pause(id uuid.UUID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	t.paused = true
+	s.unqueueAll(t)
+	return nil
+}
+
+// Resume schedules the next run of a paused task. Runs that were due while it was paused are skipped.
+func (s *Scheduler) Resume(id uuid.UUID) error {
+	defer s.flushEvents()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	if !t.paused {
+		return nil
+	}
+
+	t.paused = false
+	t.panics = 0
+	s.reschedule(t)
+	return nil
+}
+
+// RunNow runs a task right away, even if it's paused or out of its windows. The run follows the task's overlap policy
+// and doesn't change its schedule.
+func (s *Scheduler) RunNow(id uuid.UUID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	s.push(&entry{task: t, at: s.clock.Now(), kind: entryManual, attempt: 1})
+	return nil
+}
+
+// Remove stops a task like TaskConfig.Stop
+func (s *Scheduler) Remove(id uuid.UUID) error {
+	s.lock.Lock()
+	t, err := s.lookup(id)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	t.Stop()
+	return nil
+}
+
+// removeNoWait is like Remove but doesn't wait for the running handlers of the task to return
+func (s *Scheduler) removeNoWait(id uuid.UUID) error {
+	s.lock.Lock()
+	t, err := s.lookup(id)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	t.stop()
+	return nil
+}
+
+// Reschedule replaces the schedule of a task with the schedule of config, which is usually made by Every or Cron.
+// Other settings of the task, like its handler, timeout and overlap policy, don't change.
+func (s *Scheduler) Reschedule(id uuid.UUID, config *TaskConfig) error {
+	defer s.flushEvents()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	if err = t.setSchedule(config.schedule()); err != nil {
+		return err
+	}
+	t.nextStep = time.Time{}
+	t.catchUp = 0
+	t.ended = false
+
+	if t.slot != nil {
+		s.unqueueEntry(t.slot)
+	}
+	if !t.paused {
+		s.reschedule(t)
+	}
+	s.saveLocked(t)
+
+	return nil
+}
+
+// reschedule pushes the next run of t after its schedule changed. Scheduler must be locked.
+func (s *Scheduler) reschedule(t *TaskConfig) {
+	if t.slot != nil || t.overlap == OverlapDelay && t.running > 0 {
+		// next run is scheduled when the running one returns
+		return
+	}
+
+	s.pushSlot(t)
+	s.tryFinish(t)
+}
+
+// unqueueEntry takes e out of the queue. Scheduler must be locked.
+func (s *Scheduler) unqueueEntry(e *entry) {
+	if e.index >= 0 {
+		heap.Remove(&s.queue, e.index)
+	}
+	s.unqueue(e)
+	s.notify()
+}
+
+// unqueueAll takes all pending runs of t out of the queue. Scheduler must be locked.
+func (s *Scheduler) unqueueAll(t *TaskConfig) {
+	for _, e := range t.pending {
+		if e.index >= 0 {
+			heap.Remove(&s.queue, e.index)
+		}
+	}
+	t.pending = nil
+	t.queued = nil
+	t.slot = nil
+	t.trigger = nil
+	s.notify()
+}
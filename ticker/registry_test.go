@@ -0,0 +1,182 @@
+package ticker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *fakeRun) info() TaskInfo {
+	r.t.Helper()
+
+	for _, i := range r.s.Tasks() {
+		if i.Id == r.config.id {
+			return i
+		}
+	}
+
+	r.t.Fatal("task isn't scheduled")
+	return TaskInfo{}
+}
+
+func TestScheduler_Tasks(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Minutes().Name("report")
+	})
+	defer r.s.Stop()
+
+	i := r.info()
+	if i.Name != "report" || !i.NextRun.Equal(date(t, "2020-03-10 10:10")) || !i.LastRun.IsZero() || i.Runs != 0 {
+		t.Fatalf("unexpected snapshot before running %+v", i)
+	}
+	if i.Schedule.Unit != unitMinutes || i.Schedule.Interval != 10 {
+		t.Fatalf("unexpected schedule %+v", i.Schedule)
+	}
+
+	r.advanceTo("2020-03-10 10:10", true)
+	i = r.info()
+	if !i.NextRun.Equal(date(t, "2020-03-10 10:20")) || !i.LastRun.Equal(date(t, "2020-03-10 10:10")) || i.Runs != 1 {
+		t.Fatalf("unexpected snapshot after running %+v", i)
+	}
+}
+
+func TestScheduler_PauseResume(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Minutes()
+	})
+	defer r.s.Stop()
+
+	if err := r.s.Pause(r.config.id); err != nil {
+		t.Fatal(err)
+	}
+	if i := r.info(); !i.Paused || !i.NextRun.IsZero() {
+		t.Fatalf("unexpected snapshot of paused task %+v", i)
+	}
+	r.advanceTo("2020-03-10 10:30", false)
+
+	if err := r.s.Resume(r.config.id); err != nil {
+		t.Fatal(err)
+	}
+	r.advanceTo("2020-03-10 10:39", false)
+	r.advanceTo("2020-03-10 10:40", true)
+}
+
+func TestScheduler_RunNow(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Minutes()
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 10:05", false)
+	if err := r.s.RunNow(r.config.id); err != nil {
+		t.Fatal(err)
+	}
+	r.advanceTo("2020-03-10 10:05", true)
+
+	// schedule doesn't change
+	r.advanceTo("2020-03-10 10:10", true)
+}
+
+func TestScheduler_Remove(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(10).Minutes()
+	})
+	defer r.s.Stop()
+
+	if err := r.s.Remove(r.config.id); err != nil {
+		t.Fatal(err)
+	}
+	r.expectDone()
+
+	if n := len(r.s.Tasks()); n != 0 {
+		t.Fatalf("expected no tasks, got %d", n)
+	}
+	if err := r.s.Remove(r.config.id); err != ErrTaskNotFound {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	if err := r.s.Pause(uuid.New()); err != ErrTaskNotFound {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestScheduler_Reschedule(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Hour()
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 10:05", false)
+	if err := r.s.Reschedule(r.config.id, r.s.Every().Day().At(12, 0)); err != nil {
+		t.Fatal(err)
+	}
+	r.advanceTo("2020-03-10 11:00", false)
+	r.advanceTo("2020-03-10 12:00", true)
+	r.advanceTo("2020-03-11 12:00", true)
+}
+
+func TestScheduler_RescheduleCron(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		task, err := s.Cron("0 0 1 1 *")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task.In(loadLocation(t, "Asia/Tokyo"))
+	})
+	defer r.s.Stop()
+
+	if err := r.s.Reschedule(r.config.id, r.s.Every(5).Minutes()); err != nil {
+		t.Fatal(err)
+	}
+	r.s.lock.Lock()
+	cron, loc := r.config.cron, r.config.loc
+	r.s.lock.Unlock()
+	if cron != nil || loc != nil {
+		t.Fatalf("expected the cron expression and the zone to be cleared, got %v and %v", cron, loc)
+	}
+
+	r.advanceTo("2020-03-10 10:05", true)
+	r.advanceTo("2020-03-10 10:10", true)
+}
+
+func TestScheduler_RegistryConcurrency(t *testing.T) {
+	s := NewScheduler(10)
+	s.Start()
+	defer s.Stop()
+
+	var ids []uuid.UUID
+	for i := 0; i < 5; i++ {
+		task := s.Every(1).Seconds()
+		go task.Do(func(*Task) {}, nil)
+		for len(s.Tasks()) <= i {
+			time.Sleep(time.Millisecond)
+		}
+		ids = append(ids, task.id)
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Tasks()
+				s.Pause(id)
+				s.RunNow(id)
+				s.Resume(id)
+				s.Reschedule(id, s.Every(2).Seconds())
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		if err := s.Remove(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(s.Tasks()); n != 0 {
+		t.Fatalf("expected no tasks, got %d", n)
+	}
+}
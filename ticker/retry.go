@@ -0,0 +1,53 @@
+package ticker
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy schedules failed runs of a task again before its next regular run
+type RetryPolicy struct {
+	MaxAttempts int           // number of attempts including the first run
+	Delay       time.Duration // delay before the first retry
+	MaxDelay    time.Duration // upper limit of exponential delays, zero means no limit
+	Exponential bool          // doubles the delay after each retry
+	Jitter      float64       // fraction of the delay that is randomly added or subtracted, between 0 and 1
+
+	// Retryable decides which errors are retried. All errors are retried if it's nil. It's called outside of the
+	// scheduler's lock, so it can use the scheduler.
+	Retryable func(error) bool
+}
+
+// Retry runs failed runs of the task again according to p
+func (t *TaskConfig) Retry(p RetryPolicy) *TaskConfig {
+	t.retry = &p
+	return t
+}
+
+// shouldRetry reports whether a run that failed with err on attempt should be retried
+func (p *RetryPolicy) shouldRetry(err error, attempt int) bool {
+	if err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// delay returns the time to wait after the given failed attempt. r is the source of the jitter.
+func (p *RetryPolicy) delay(attempt int, r *rand.Rand) time.Duration {
+	d := p.Delay
+	if p.Exponential {
+		for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+			d *= 2
+		}
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+	}
+
+	if p.Jitter > 0 {
+		d += time.Duration((r.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+
+	return d
+}
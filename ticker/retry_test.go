@@ -0,0 +1,126 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	tests := []struct {
+		policy  RetryPolicy
+		attempt int
+		delay   time.Duration
+	}{
+		{RetryPolicy{Delay: time.Second}, 1, time.Second},
+		{RetryPolicy{Delay: time.Second}, 5, time.Second},
+		{RetryPolicy{Delay: time.Second, Exponential: true}, 1, time.Second},
+		{RetryPolicy{Delay: time.Second, Exponential: true}, 4, 8 * time.Second},
+		{RetryPolicy{Delay: time.Second, Exponential: true, MaxDelay: 5 * time.Second}, 4, 5 * time.Second},
+		{RetryPolicy{Delay: time.Second, Exponential: true, MaxDelay: time.Minute}, 100, time.Minute},
+	}
+
+	for _, test := range tests {
+		if d := test.policy.delay(test.attempt, nil); d != test.delay {
+			t.Errorf("%+v attempt %d: expected %s, got %s", test.policy, test.attempt, test.delay, d)
+		}
+	}
+
+	p := RetryPolicy{Delay: 10 * time.Second, Jitter: 0.5}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if d := p.delay(1, r); d < 5*time.Second || d > 15*time.Second {
+			t.Fatalf("jittered delay %s is out of range", d)
+		}
+	}
+}
+
+func TestTaskConfig_Retry(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errPermanent := errors.New("permanent")
+
+	type run struct {
+		at      string
+		attempt int
+	}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		errs   []error
+		runs   []run
+	}{
+		{
+			name:   "succeeds on the third attempt",
+			policy: RetryPolicy{MaxAttempts: 5, Delay: 10 * time.Second, Exponential: true},
+			errs:   []error{errTemporary, errTemporary, nil, nil},
+			runs:   []run{{"2020-03-10 02:00:00", 1}, {"2020-03-10 02:00:10", 2}, {"2020-03-10 02:00:30", 3}, {"2020-03-11 02:00:00", 1}},
+		},
+		{
+			name:   "gives up after max attempts",
+			policy: RetryPolicy{MaxAttempts: 2, Delay: time.Minute},
+			errs:   []error{errTemporary, errTemporary, errTemporary},
+			runs:   []run{{"2020-03-10 02:00:00", 1}, {"2020-03-10 02:01:00", 2}, {"2020-03-11 02:00:00", 1}},
+		},
+		{
+			name: "doesn't retry permanent errors",
+			policy: RetryPolicy{MaxAttempts: 5, Delay: time.Minute, Retryable: func(err error) bool {
+				return err != errPermanent
+			}},
+			errs: []error{errPermanent, nil},
+			runs: []run{{"2020-03-10 02:00:00", 1}, {"2020-03-11 02:00:00", 1}},
+		},
+	}
+
+	for _, test := range tests {
+		clock := NewFakeClock(date(t, "2020-03-10 00:00"))
+		s := NewScheduler(10).WithClock(clock)
+		s.Start()
+
+		type result struct {
+			at      time.Time
+			attempt int
+		}
+		results := make(chan result)
+		n := 0
+		go s.Every().Day().At(2, 0).Retry(test.policy).DoContext(func(ctx context.Context, task *Task) error {
+			results <- result{clock.Now(), task.Attempt}
+			err := test.errs[n]
+			n++
+			return err
+		}, nil)
+
+		for _, r := range test.runs {
+			at, _ := time.ParseInLocation("2006-01-02 15:04:05", r.at, time.UTC)
+			clock.BlockUntil(1)
+			clock.Advance(at.Sub(clock.Now()))
+
+			select {
+			case got := <-results:
+				if !got.at.Equal(at) || got.attempt != r.attempt {
+					t.Errorf("%s: expected attempt %d at %s, got %d at %s", test.name, r.attempt, at, got.attempt, got.at)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("%s: expected attempt %d at %s", test.name, r.attempt, at)
+			}
+		}
+
+		s.Stop()
+	}
+}
+
+func TestRetryPolicy_RetryableUsesScheduler(t *testing.T) {
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Hour().Retry(RetryPolicy{MaxAttempts: 2, Delay: time.Minute, Retryable: func(error) bool {
+			return len(s.Tasks()) == 1
+		}})
+	}, func(r *fakeRun, ctx context.Context, n int) error {
+		return errors.New("failed")
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 01:00", true)
+	r.advanceTo("2020-03-10 01:01", true)
+}
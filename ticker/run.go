@@ -0,0 +1,323 @@
+package ticker
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// push queues a pending run of t. Scheduler must be locked.
+func (s *Scheduler) push(e *entry) {
+	t := e.task
+	t.pending = append(t.pending, e)
+	heap.Push(&s.queue, e)
+	s.notify()
+}
+
+// pushSlot queues the next run of t's schedule. It marks t as ended and returns false if there's none.
+// Scheduler must be locked.
+func (s *Scheduler) pushSlot(t *TaskConfig) bool {
+	if !t.timed() {
+		// only triggers run it
+		return true
+	}
+
+	next, catchUp := t.advance(s.clock.Now())
+	next = s.nextAllowed(t, next)
+	if next.IsZero() || t.to.Year() != 1 && next.After(t.to) {
+		t.ended = true
+		return false
+	}
+
+	if !catchUp {
+		// calendar schedules count the next run from this one, so catch-up runs would move them
+		t.nextStep = next
+	}
+	t.slot = &entry{task: t, at: next.Add(s.jitterDelay(t)), slot: next, kind: entrySlot, attempt: 1}
+	s.push(t.slot)
+
+	return true
+}
+
+// unqueue forgets e after it's popped from the queue. Scheduler must be locked.
+func (s *Scheduler) unqueue(e *entry) {
+	t := e.task
+	for i, p := range t.pending {
+		if p == e {
+			t.pending = append(t.pending[:i], t.pending[i+1:]...)
+			break
+		}
+	}
+
+	if t.slot == e {
+		t.slot = nil
+	}
+}
+
+// tryFinish finishes t if its schedule is over and nothing of it is left to run. Scheduler must be locked.
+func (s *Scheduler) tryFinish(t *TaskConfig) bool {
+	if t.removed || !t.ended || t.running > 0 || len(t.pending) > 0 || len(t.queued) > 0 {
+		return false
+	}
+
+	s.finish(t)
+	return true
+}
+
+// due decides what to do with a run that's due. It returns true if e should be sent to a worker.
+// Scheduler must be locked.
+func (s *Scheduler) due(e *entry, now time.Time) bool {
+	t := e.task
+	s.unqueue(e)
+	if t.removed {
+		return false
+	}
+
+	// manual runs ignore the schedule
+	if at := s.allowedFrom(t, now); e.kind != entryManual && !at.Equal(now) {
+		// a blackout was registered or the clock jumped into it after the run was scheduled
+		if e.kind == entrySlot {
+			s.missedLocked(t, e.at, 1, MissedBlackout)
+			s.pushSlot(t)
+		} else if !at.IsZero() {
+			e.at = at
+			s.push(e)
+		} else {
+			s.dropTriggered(t, e)
+		}
+		s.tryFinish(t)
+		return false
+	}
+
+	if e.kind == entrySlot {
+		if !now.After(t.from) {
+			// too early, skip this run
+			s.pushSlot(t)
+			s.tryFinish(t)
+			return false
+		}
+
+		t.slotsRun++
+		if t.overlap != OverlapDelay {
+			// next run doesn't depend on this one
+			s.pushSlot(t)
+		}
+	}
+
+	if t.running >= t.limit() {
+		if !t.overlapped(e) {
+			s.missedLocked(t, e.at, 1, MissedOverlap)
+			s.dropTriggered(t, e)
+		}
+		s.tryFinish(t)
+		return false
+	}
+
+	t.running++
+	return true
+}
+
+// dispatch sleeps until the earliest run is due and sends due runs to workers
+func (s *Scheduler) dispatch(stopped <-chan struct{}) {
+	defer s.loopGroup.Done()
+
+	var timer Timer
+	defer func() {
+		if timer != nil {
+			stopTimer(timer)
+		}
+	}()
+
+	var ready []*entry
+	for {
+		s.lock.Lock()
+		now := s.clock.Now()
+		ready = ready[:0]
+		for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+			e := heap.Pop(&s.queue).(*entry)
+			if s.due(e, now) {
+				ready = append(ready, e)
+			}
+		}
+
+		// the timer is armed at the time of the next run, since the clock may move before it's armed
+		var next time.Time
+		if len(s.queue) > 0 {
+			next = s.queue[0].at
+		}
+		s.lock.Unlock()
+		s.flushEvents()
+
+		for _, e := range ready {
+			select {
+			case s.jobs <- e:
+			case <-stopped:
+				return
+			}
+		}
+
+		var timerC <-chan time.Time
+		if !next.IsZero() {
+			if timer == nil {
+				timer = s.clock.NewTimerAt(next)
+			} else {
+				resetTimerAt(timer, next)
+			}
+			timerC = timer.C()
+		} else if timer != nil {
+			stopTimer(timer)
+		}
+
+		select {
+		case <-timerC:
+		case <-s.wake:
+		case <-stopped:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) work(jobs <-chan *entry, stopped <-chan struct{}) {
+	defer s.loopGroup.Done()
+
+	for {
+		select {
+		case e := <-jobs:
+			// run the queued runs of the same task right after
+			for e != nil {
+				e = s.run(e)
+			}
+		case <-stopped:
+			return
+		}
+	}
+}
+
+// call calls handler and turns its panic into an error
+func call(ctx context.Context, handler ContextTaskFunc, task *Task) (panicValue interface{}, err error) {
+	defer func() {
+		if panicValue = recover(); panicValue != nil {
+			err = newPanicError(panicValue)
+		}
+	}()
+
+	return nil, handler(ctx, task)
+}
+
+// run runs the handler for e and returns the next queued run of the task if there's any
+func (s *Scheduler) run(e *entry) (next *entry) {
+	t := e.task
+
+	s.lock.Lock()
+	ctx := t.ctx
+	var cancel context.CancelFunc
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	if e.kind == entryTrigger {
+		s.startTriggered(t, e)
+	}
+
+	// each run gets a copy so parallel runs don't race
+	task := *t.task
+	task.Attempt = e.attempt
+	task.Elapsed = s.clock.Since(t.lastRun)
+	handler := t.handler
+	retry := t.retry
+	hooks := s.hooksOf(t)
+	locker, ttl := s.locker, s.lockTTL
+	lease := Lease{Name: t.name, Owner: s.owner}
+	s.lock.Unlock()
+
+	var err error
+	event := Event{Id: t.id, Name: t.name, Attempt: e.attempt, Scheduled: e.at}
+	held, acquired := s.acquire(locker, lease, ttl, e)
+	called := acquired && handler != nil && ctx.Err() == nil
+	if called {
+		stopHeartbeat := s.heartbeat(locker, held, ttl, cancel)
+		event.Start = s.clock.Now()
+		fire(hooks, hookStart, event)
+		event.Panic, err = call(ctx, handler, &task)
+		event.End = s.clock.Now()
+		event.Duration = event.End.Sub(event.Start)
+		event.Err = err
+		stopHeartbeat()
+	}
+	cancel()
+	if held != nil {
+		s.storeError(locker.Release(*held))
+	}
+	// Retryable is called before locking, so it can use the scheduler
+	retried := called && retry != nil && retry.shouldRetry(err, e.attempt)
+
+	s.lock.Lock()
+	sink := s.historySink
+	defer func() {
+		s.lock.Unlock()
+
+		if called {
+			if sink != nil {
+				s.storeError(sink.Record(event.runRecord()))
+			}
+
+			if event.Panic != nil {
+				fire(hooks, hookPanic, event)
+			} else if err != nil {
+				fire(hooks, hookError, event)
+			} else {
+				fire(hooks, hookSuccess, event)
+			}
+		}
+		s.flushEvents()
+	}()
+
+	if !acquired {
+		s.missedLocked(t, e.at, 1, MissedLease)
+	} else {
+		t.lastRun = s.clock.Now()
+		t.task.Payload = task.Payload
+		t.task.Elapsed = task.Elapsed
+		t.task.Attempt = task.Attempt
+		t.task.Err = err
+		t.task.Runs++
+		if err != nil {
+			t.task.Failures++
+		}
+		if event.Panic != nil {
+			s.panicked(t)
+		}
+		if called {
+			s.record(t, event.runRecord())
+		}
+	}
+
+	if !t.removed && !t.paused {
+		if retried {
+			s.push(&entry{
+				task:    t,
+				at:      t.lastRun.Add(retry.delay(e.attempt, s.random())),
+				kind:    entryRetry,
+				attempt: e.attempt + 1,
+			})
+		} else if t.overlap == OverlapDelay && t.slot == nil && !t.ended {
+			s.pushSlot(t)
+		}
+	}
+
+	if !t.removed && len(t.queued) > 0 && t.ctx.Err() == nil {
+		next = t.queued[0]
+		t.queued = t.queued[1:]
+		return
+	}
+
+	t.running--
+	if !s.tryFinish(t) {
+		s.saveLocked(t)
+	}
+	s.release(t)
+
+	return
+}
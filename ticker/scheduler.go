@@ -0,0 +1,304 @@
+package ticker
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scheduler owns a set of tasks, their limits and their lifecycle.
+// Tasks configured before Start is called wait for it before they begin running.
+//
+// A single dispatcher goroutine sleeps until the earliest next run of all tasks and hands due tasks to a bounded
+// pool of workers which run the handlers.
+type Scheduler struct {
+	clock        Clock
+	workers      int
+	drainTimeout time.Duration
+
+	lock      sync.Mutex
+	running   bool
+	started   chan struct{} // closed by Start
+	stopped   chan struct{} // closed by Stop
+	ctx       context.Context
+	cancelAll context.CancelFunc
+	openTasks chan struct{}
+	queue     entryHeap
+	wake      chan struct{}  // wakes up the dispatcher when the queue changes
+	jobs      chan *entry    // due runs waiting for a worker
+	waitGroup sync.WaitGroup // Do calls
+	loopGroup sync.WaitGroup // dispatcher and workers
+
+	tasks             map[uuid.UUID]*TaskConfig // scheduled tasks
+	named             map[string]*TaskConfig
+	handlers          map[string]ContextTaskFunc
+	blackouts         map[string]Blackout
+	hooks             []Hooks
+	maxPanics         int
+	historySize       int
+	historySink       HistorySink
+	events            []pendingEvent // events waiting for the lock to be released
+	store             JobStore
+	storeLock         sync.Mutex           // keeps writes to the store in the order they were queued
+	writes            []storeWrite         // writes to the store waiting for the lock to be released
+	records           map[string]JobRecord // loaded from store
+	missedRunPolicy   MissedRunPolicy
+	storeErrorHandler func(error)
+	locker            Locker
+	lockTTL           time.Duration
+	owner             string // owner of the leases taken from locker
+	rand              *rand.Rand
+}
+
+// NewScheduler creates a stopped scheduler that runs at most maxTasks tasks at the same time.
+func NewScheduler(maxTasks int) *Scheduler {
+	return &Scheduler{
+		clock:        RealClock(),
+		workers:      Config.Workers,
+		drainTimeout: Config.DrainTimeout,
+		started:      make(chan struct{}),
+		stopped:      make(chan struct{}),
+		openTasks:    make(chan struct{}, maxTasks),
+		wake:         make(chan struct{}, 1),
+		tasks:        make(map[uuid.UUID]*TaskConfig),
+		named:        make(map[string]*TaskConfig),
+		handlers:     make(map[string]ContextTaskFunc),
+		blackouts:    make(map[string]Blackout),
+		historySize:  DefaultHistorySize,
+	}
+}
+
+// WithClock replaces the clock of the scheduler. It must be called before any task is configured.
+func (s *Scheduler) WithClock(c Clock) *Scheduler {
+	s.clock = c
+	return s
+}
+
+// WithWorkers sets the number of handlers that can run at the same time. It must be called before Start.
+func (s *Scheduler) WithWorkers(n int) *Scheduler {
+	s.workers = n
+	return s
+}
+
+// WithDrainTimeout sets how long stopping waits for running handlers after cancelling them. Zero waits forever.
+func (s *Scheduler) WithDrainTimeout(d time.Duration) *Scheduler {
+	s.drainTimeout = d
+	return s
+}
+
+// Start starts processing tasks. Calling it on a running scheduler does nothing.
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.running = true
+	s.jobs = make(chan *entry)
+	s.ctx, s.cancelAll = context.WithCancel(context.Background())
+
+	s.loopGroup.Add(1 + s.workers)
+	go s.dispatch(s.stopped)
+	for i := 0; i < s.workers; i++ {
+		go s.work(s.jobs, s.stopped)
+	}
+
+	close(s.started)
+}
+
+// Stop stops processing all tasks and waits for them to return.
+// Contexts of running handlers are cancelled and they're waited for up to the drain timeout.
+// The scheduler can be started again afterwards.
+func (s *Scheduler) Stop() {
+	s.lock.Lock()
+	if s.running {
+		s.running = false
+		s.cancelAll()
+		close(s.stopped)
+		s.started = make(chan struct{})
+		s.stopped = make(chan struct{})
+	}
+	s.lock.Unlock()
+
+	loopDone := make(chan struct{})
+	go func() {
+		s.loopGroup.Wait()
+		close(loopDone)
+	}()
+	s.drain(loopDone)
+
+	s.Wait()
+}
+
+// drain waits for done up to the drain timeout
+func (s *Scheduler) drain(done <-chan struct{}) {
+	if s.drainTimeout <= 0 {
+		<-done
+		return
+	}
+
+	timer := time.NewTimer(s.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
+
+// Wait blocks until all tasks of this scheduler return
+func (s *Scheduler) Wait() { s.waitGroup.Wait() }
+
+// NumTasks returns the number of running tasks
+func (s *Scheduler) NumTasks() int { return len(s.openTasks) }
+
+// Every begins configuring a task on this scheduler. Supply zero or one intervals. No intervals will be counted as 1
+func (s *Scheduler) Every(interval ...int) *TaskConfig {
+	i := 1
+	if len(interval) > 0 {
+		i = interval[0]
+	}
+
+	now := s.clock.Now()
+	return &TaskConfig{
+		scheduler:  s,
+		oneShot:    false,
+		lastRun:    now,
+		interval:   time.Duration(i),
+		weekDay:    now.Weekday(),
+		monthDay:   now.Day(),
+		month:      now.Month(),
+		hour:       now.Hour(),
+		minute:     now.Minute(),
+		shouldStop: make(chan struct{}, 1),
+	}
+}
+
+// Cron begins configuring a task from a cron expression.
+// It accepts the standard 5 fields (minute hour day-of-month month day-of-week), an optional leading seconds field
+// and the macros @yearly, @monthly, @weekly, @daily and @hourly.
+func (s *Scheduler) Cron(expr string) (*TaskConfig, error) {
+	c, err := parseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	t := s.Every()
+	t.cron = c
+	return t, nil
+}
+
+// signals returns the channels of the current run of the scheduler
+func (s *Scheduler) signals() (started, stopped <-chan struct{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.started, s.stopped
+}
+
+// add schedules the first run of t. It returns false if t has nothing to run.
+func (s *Scheduler) add(t *TaskConfig) bool {
+	if t.name != "" {
+		s.storeError(s.loadRecords())
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t.done = make(chan struct{})
+	t.idle = make(chan struct{})
+	t.ctx, t.cancelRuns = context.WithCancel(s.ctx)
+	if t.name != "" {
+		if _, ok := s.named[t.name]; ok {
+			return false
+		}
+		s.restore(t)
+	}
+
+	if !s.pushSlot(t) {
+		return false
+	}
+
+	s.tasks[t.id] = t
+	if t.name != "" {
+		s.named[t.name] = t
+		s.saveLocked(t)
+	}
+
+	return true
+}
+
+// remove takes t out of the queue and cancels its runs. Scheduler must not be locked.
+func (s *Scheduler) remove(t *TaskConfig) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removeLocked(t)
+}
+
+func (s *Scheduler) removeLocked(t *TaskConfig) {
+	if t.removed {
+		return
+	}
+
+	delete(s.tasks, t.id)
+	if t.name != "" {
+		delete(s.named, t.name)
+	}
+	t.removed = true
+	if t.cancelRuns != nil {
+		t.cancelRuns()
+	}
+
+	s.unqueueAll(t)
+	s.release(t)
+}
+
+// finish removes t when its schedule is over and unblocks Do
+func (s *Scheduler) finish(t *TaskConfig) {
+	s.removeLocked(t)
+	s.forgetLocked(t)
+	close(t.done)
+}
+
+// release closes t.idle when a removed task has no running handlers
+func (s *Scheduler) release(t *TaskConfig) {
+	if t.removed && t.running == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// cancel removes t, cancels its runs and returns a channel that's closed when all of them return.
+// It returns nil if t isn't running.
+func (s *Scheduler) cancel(t *TaskConfig) <-chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if t.done == nil {
+		return nil
+	}
+
+	idle := t.idle
+	s.removeLocked(t)
+	return idle
+}
+
+// random returns the scheduler's source of random delays. Scheduler must be locked.
+func (s *Scheduler) random() *rand.Rand {
+	if s.rand == nil {
+		// the global source isn't seeded before Go 1.20, so replicas would all get the same delays
+		s.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return s.rand
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
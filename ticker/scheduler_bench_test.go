@@ -0,0 +1,58 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package ticker
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func cpuTime() time.Duration {
+	var ru syscall.Rusage
+	syscall.Getrusage(syscall.RUSAGE_SELF, &ru)
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// BenchmarkScheduler_Idle reports the CPU time used per millisecond while 10k tasks wait for their next run.
+// With one polling goroutine per task this didn't finish within minutes.
+func BenchmarkScheduler_Idle(b *testing.B) {
+	const tasks = 10000
+
+	s := NewScheduler(tasks)
+	s.Start()
+	defer s.Stop()
+
+	for i := 0; i < tasks; i++ {
+		go s.Every().Hour().Do(nil, nil)
+	}
+	for s.NumTasks() != tasks {
+		time.Sleep(time.Millisecond)
+	}
+
+	b.ResetTimer()
+	start := cpuTime()
+	for i := 0; i < b.N; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	b.ReportMetric(float64(cpuTime()-start)/float64(b.N), "cpu-ns/op")
+}
+
+func BenchmarkScheduler_Dispatch(b *testing.B) {
+	clock := NewFakeClock(time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC))
+	s := NewScheduler(1).WithClock(clock)
+	s.Start()
+	defer s.Stop()
+
+	ran := make(chan struct{})
+	go s.Every().Second().Do(func(*Task) { ran <- struct{}{} }, nil)
+	clock.BlockUntil(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		clock.Advance(time.Second)
+		<-ran
+		clock.BlockUntil(1)
+	}
+}
@@ -0,0 +1,209 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitTasks(t *testing.T, s *Scheduler, n int) {
+	deadline := time.Now().Add(time.Second)
+	for s.NumTasks() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d tasks, got %d", n, s.NumTasks())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler_Independent(t *testing.T) {
+	a := NewScheduler(10)
+	b := NewScheduler(10)
+	a.Start()
+	b.Start()
+	defer b.Stop()
+
+	go a.Every().Hour().Do(nil, nil)
+	go b.Every().Hour().Do(nil, nil)
+	go b.Every().Hour().Do(nil, nil)
+	waitTasks(t, a, 1)
+	waitTasks(t, b, 2)
+
+	a.Stop()
+	if a.NumTasks() != 0 {
+		t.Errorf("expected stopped scheduler to have no tasks, got %d", a.NumTasks())
+	}
+	if b.NumTasks() != 2 {
+		t.Errorf("expected other scheduler to keep its tasks, got %d", b.NumTasks())
+	}
+}
+
+func TestScheduler_StartAfterEvery(t *testing.T) {
+	s := NewScheduler(10)
+
+	go s.Every().Hour().Do(nil, nil)
+	time.Sleep(10 * time.Millisecond)
+	if s.NumTasks() != 0 {
+		t.Fatal("task started before the scheduler")
+	}
+
+	s.Start()
+	waitTasks(t, s, 1)
+	s.Stop()
+	waitTasks(t, s, 0)
+}
+
+func TestTaskConfig_Timeout(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	s := NewScheduler(10).WithClock(clock)
+	s.Start()
+	defer s.Stop()
+
+	done := make(chan *TaskConfig)
+	go func() {
+		done <- s.Every().Second().Once().Timeout(10*time.Millisecond).DoContext(func(ctx context.Context, task *Task) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	select {
+	case task := <-done:
+		if task.task.Err != context.DeadlineExceeded || task.task.Failures != 1 {
+			t.Fatalf("expected a timeout failure, got %v %d", task.task.Err, task.task.Failures)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler didn't time out")
+	}
+}
+
+func TestTaskConfig_Failures(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	s := NewScheduler(10).WithClock(clock)
+	s.Start()
+	defer s.Stop()
+
+	fail := errors.New("fail")
+	runs := make(chan struct{})
+	config := s.Every().Second()
+	go config.DoContext(func(ctx context.Context, task *Task) error {
+		defer func() { runs <- struct{}{} }()
+		if task.Failures == 0 {
+			return fail
+		}
+		return nil
+	}, nil)
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		<-runs
+	}
+	clock.BlockUntil(1)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if config.task.Err != nil || config.task.Failures != 1 {
+		t.Fatalf("expected a successful run after one failure, got %v %d", config.task.Err, config.task.Failures)
+	}
+}
+
+// waitCancel returns a handler that blocks until its run is cancelled and then closes cancelled
+func waitCancel(cancelled chan struct{}) fakeHandler {
+	return func(r *fakeRun, ctx context.Context, n int) error {
+		r.block(ctx, nil)
+		close(cancelled)
+		return nil
+	}
+}
+
+func everySecond(s *Scheduler) *TaskConfig { return s.Every().Second() }
+
+func TestScheduler_StopCancelsHandlers(t *testing.T) {
+	cancelled := make(chan struct{})
+	r := startFakeHandler(t, "2020-03-10 00:00", everySecond, waitCancel(cancelled))
+	r.advance(time.Second)
+	r.expectRuns(1)
+	r.s.Stop()
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("expected handler context to be cancelled")
+	}
+}
+
+func TestScheduler_DrainTimeout(t *testing.T) {
+	// handler that ignores its context
+	r := startFakeHandler(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		s.WithDrainTimeout(20 * time.Millisecond)
+		return everySecond(s)
+	}, func(r *fakeRun, ctx context.Context, n int) error {
+		r.block(context.Background(), r.release)
+		return nil
+	})
+	defer close(r.release)
+	r.advance(time.Second)
+	r.expectRuns(1)
+
+	stopped := make(chan struct{})
+	go func() {
+		r.s.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop didn't return after the drain timeout")
+	}
+}
+
+func TestTaskConfig_StopCancelsRun(t *testing.T) {
+	cancelled := make(chan struct{})
+	r := startFakeHandler(t, "2020-03-10 00:00", everySecond, waitCancel(cancelled))
+	defer r.s.Stop()
+	r.advance(time.Second)
+	r.expectRuns(1)
+	r.config.Stop()
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("expected handler context to be cancelled")
+	}
+	waitTasks(t, r.s, 0)
+}
+
+func TestDefault(t *testing.T) {
+	// other tests may have used the default scheduler already, so a new one is created and the old one is put back
+	saved := defaultScheduler
+	defaultOnce, defaultScheduler = sync.Once{}, nil
+	defer func() {
+		defaultOnce, defaultScheduler = sync.Once{}, saved
+		if saved != nil {
+			defaultOnce.Do(func() {})
+		}
+	}()
+
+	workers := Config.Workers
+	Config.Workers = 3
+	defer func() { Config.Workers = workers }()
+
+	s := Default()
+	defer s.Stop()
+	if s != Default() || s.workers != 3 {
+		t.Fatalf("expected one default scheduler with 3 workers, got %d", s.workers)
+	}
+
+	s.lock.Lock()
+	running := s.running
+	s.lock.Unlock()
+	if !running {
+		t.Fatal("expected the default scheduler to be started")
+	}
+}
@@ -0,0 +1,102 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/kiyoptr/su/db"
+	"github.com/kiyoptr/su/ticker"
+)
+
+type Lease struct {
+	db.BaseModel
+	Name    string `gorm:"unique_index;not null"`
+	Owner   string
+	Slot    time.Time
+	Expires time.Time
+	Done    bool
+	Version int // changes each time the lease is taken, so takeovers don't race
+}
+
+func (Lease) TableName() string { return "ticker_lease" }
+
+func init() {
+	db.DefineModel(&Lease{})
+}
+
+// Locker is a ticker.Locker that keeps one lease for each task in the ticker_lease table.
+// db.CheckModelTables must be called before using it.
+type Locker struct {
+	dc *gorm.DB
+}
+
+func NewLocker(dc *gorm.DB) *Locker {
+	return &Locker{dc: dc}
+}
+
+func (l *Locker) Acquire(lease ticker.Lease, now time.Time) (bool, error) {
+	result, err := db.QuerySingle(l.dc, &Lease{}, nil, byName(lease.Name))
+	if err != nil {
+		return false, db.ErrQuery(err, &Lease{}, lease.Name)
+	}
+
+	if result == nil {
+		row := &Lease{Name: lease.Name, Owner: lease.Owner, Slot: lease.Slot, Expires: lease.Expires}
+		if err = db.Create(l.dc, row); err != nil {
+			// another owner may have created it first
+			if result, _ = db.QuerySingle(l.dc, &Lease{}, nil, byName(lease.Name)); result != nil {
+				return false, nil
+			}
+			return false, db.ErrCreate(err, row, lease.Name)
+		}
+		return true, nil
+	}
+
+	row := result.(*Lease)
+	if row.Done && !row.Slot.Before(lease.Slot) {
+		// the slot already ran
+		return false, nil
+	}
+	if !row.Done && row.Expires.After(now) {
+		// the holder is still running
+		return false, nil
+	}
+
+	update := l.dc.Model(&Lease{}).
+		Where("id = ? AND version = ?", row.ID, row.Version).
+		Updates(map[string]interface{}{
+			"owner":   lease.Owner,
+			"slot":    lease.Slot,
+			"expires": lease.Expires,
+			"done":    false,
+			"version": row.Version + 1,
+		})
+	if update.Error != nil {
+		return false, db.ErrUpdate(update.Error, row, lease.Name)
+	}
+
+	// nothing is updated if another owner took it first
+	return update.RowsAffected == 1, nil
+}
+
+func (l *Locker) Renew(lease ticker.Lease) (bool, error) {
+	update := l.held(lease).Update("expires", lease.Expires)
+	if update.Error != nil {
+		return false, db.ErrUpdate(update.Error, &Lease{}, lease.Name)
+	}
+
+	return update.RowsAffected == 1, nil
+}
+
+func (l *Locker) Release(lease ticker.Lease) error {
+	if err := l.held(lease).Update("done", true).Error; err != nil {
+		return db.ErrUpdate(err, &Lease{}, lease.Name)
+	}
+
+	return nil
+}
+
+// held selects the lease if its owner still holds it
+func (l *Locker) held(lease ticker.Lease) *gorm.DB {
+	return l.dc.Model(&Lease{}).Where("name = ? AND owner = ? AND done = ?", lease.Name, lease.Owner, false)
+}
@@ -0,0 +1,120 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/kiyoptr/su/db"
+	"github.com/kiyoptr/su/ticker"
+)
+
+func openMem(t *testing.T) *gorm.DB {
+	dc, err := db.OpenMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: is a new database
+	dc.DB().SetMaxOpenConns(1)
+
+	if err = db.CheckModelTables(dc); err != nil {
+		dc.Close()
+		t.Fatal(err)
+	}
+
+	return dc
+}
+
+func TestLocker(t *testing.T) {
+	dc := openMem(t)
+	defer dc.Close()
+
+	l := NewLocker(dc)
+	now := time.Date(2020, 3, 10, 2, 0, 0, 0, time.UTC)
+	a := ticker.Lease{Name: "emails", Owner: "a", Slot: now, Expires: now.Add(time.Minute)}
+	b := ticker.Lease{Name: "emails", Owner: "b", Slot: now, Expires: now.Add(time.Minute)}
+
+	acquire := func(lease ticker.Lease, now time.Time, expected bool) {
+		t.Helper()
+		ok, err := l.Acquire(lease, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Fatalf("expected %s acquiring slot %s at %s to be %v", lease.Owner, lease.Slot, now, expected)
+		}
+	}
+	renew := func(lease ticker.Lease, expected bool) {
+		t.Helper()
+		ok, err := l.Renew(lease)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Fatalf("expected %s renewing to be %v", lease.Owner, expected)
+		}
+	}
+
+	// a runs the slot and b can't run it while it's running or after it's done
+	acquire(a, now, true)
+	acquire(b, now.Add(time.Second), false)
+	a.Expires = now.Add(2 * time.Minute)
+	renew(a, true)
+	acquire(b, now.Add(90*time.Second), false)
+	if err := l.Release(a); err != nil {
+		t.Fatal(err)
+	}
+	acquire(b, now.Add(3*time.Minute), false)
+
+	// b runs the next slot and crashes, so a takes it over after it expires
+	next := now.Add(time.Hour)
+	b.Slot, b.Expires = next, next.Add(time.Minute)
+	acquire(b, next, true)
+	a.Slot, a.Expires = next, next.Add(2*time.Minute)
+	acquire(a, next.Add(30*time.Second), false)
+	acquire(a, next.Add(time.Minute), true)
+
+	// b lost the lease
+	b.Expires = next.Add(3 * time.Minute)
+	renew(b, false)
+	if err := l.Release(b); err != nil {
+		t.Fatal(err)
+	}
+	renew(a, true)
+}
+
+func TestLocker_Scheduler(t *testing.T) {
+	dc := openMem(t)
+	defer dc.Close()
+
+	// two replicas run the same task and only one of them runs each slot
+	clock := ticker.NewFakeClock(time.Date(2020, 3, 10, 1, 59, 0, 0, time.UTC))
+	runs := make(chan string, 10)
+	missed := make(chan string, 10)
+	for _, name := range []string{"a", "b"} {
+		name := name
+		s := ticker.NewScheduler(10).WithClock(clock).WithLocker(NewLocker(dc), time.Minute).WithHooks(ticker.Hooks{
+			OnMissed: func(e ticker.Event) { missed <- name },
+		})
+		s.Start()
+		defer s.Stop()
+
+		go s.Every().Day().At(2, 0).Name("emails").Do(func(*ticker.Task) { runs <- name }, nil)
+	}
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+
+	ran := 0
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+			ran++
+		case <-missed:
+		case <-time.After(time.Second):
+			t.Fatal("expected the slot to run on one replica and be missed on the other")
+		}
+	}
+	if ran != 1 {
+		t.Fatalf("expected one run, got %d", ran)
+	}
+}
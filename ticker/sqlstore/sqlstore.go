@@ -0,0 +1,100 @@
+// Package sqlstore implements ticker.JobStore and ticker.Locker on a SQL database through the db package.
+package sqlstore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/kiyoptr/su/db"
+	"github.com/kiyoptr/su/ticker"
+)
+
+type Job struct {
+	db.BaseModel
+	Name     string `gorm:"unique_index;not null"`
+	Schedule string `gorm:"type:text"`
+	LastRun  time.Time
+}
+
+func (Job) TableName() string { return "ticker_job" }
+
+func init() {
+	db.DefineModel(&Job{})
+}
+
+// Store is a ticker.JobStore that keeps records in the ticker_job table.
+// db.CheckModelTables must be called before using it.
+type Store struct {
+	dc *gorm.DB
+}
+
+func New(dc *gorm.DB) *Store {
+	return &Store{dc: dc}
+}
+
+func byName(name string) db.ScopeFunc {
+	return func(dc *gorm.DB) *gorm.DB {
+		return dc.Where("name = ?", name)
+	}
+}
+
+func (s *Store) Load() (list []ticker.JobRecord, err error) {
+	result, err := db.QueryAll(s.dc, &Job{}, nil)
+	if err != nil {
+		return nil, db.ErrQuery(err, &Job{})
+	}
+
+	jobs, _ := result.([]Job)
+	list = make([]ticker.JobRecord, 0, len(jobs))
+	for _, j := range jobs {
+		r := ticker.JobRecord{
+			Name:    j.Name,
+			LastRun: j.LastRun,
+		}
+		if err = json.Unmarshal([]byte(j.Schedule), &r.Schedule); err != nil {
+			return nil, db.ErrQuery(err, &j, j.Name)
+		}
+		list = append(list, r)
+	}
+
+	return
+}
+
+func (s *Store) Save(r ticker.JobRecord) error {
+	schedule, err := json.Marshal(r.Schedule)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.QuerySingle(s.dc, &Job{}, nil, byName(r.Name))
+	if err != nil {
+		return db.ErrQuery(err, &Job{}, r.Name)
+	}
+
+	if result == nil {
+		j := &Job{Name: r.Name, Schedule: string(schedule), LastRun: r.LastRun}
+		if err = db.Create(s.dc, j); err != nil {
+			return db.ErrCreate(err, j, r.Name)
+		}
+		return nil
+	}
+
+	j := result.(*Job)
+	j.Schedule = string(schedule)
+	j.LastRun = r.LastRun
+	if err = db.Update(s.dc, j, "Schedule", "LastRun"); err != nil {
+		return db.ErrUpdate(err, j, r.Name)
+	}
+
+	return nil
+}
+
+func (s *Store) Delete(name string) error {
+	// unscoped so the unique name can be used again
+	if err := s.dc.Unscoped().Where("name = ?", name).Delete(&Job{}).Error; err != nil {
+		return db.ErrDelete(err, &Job{}, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kiyoptr/su/db"
+	"github.com/kiyoptr/su/ticker"
+)
+
+func TestStore(t *testing.T) {
+	dc, err := db.OpenMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dc.Close()
+	// every connection to :memory: is a new database
+	dc.DB().SetMaxOpenConns(1)
+
+	if err = db.CheckModelTables(dc); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(dc)
+	lastRun := time.Date(2020, 3, 10, 2, 0, 0, 0, time.UTC)
+	r := ticker.JobRecord{
+		Name:     "report",
+		Schedule: ticker.Schedule{Cron: "0 2 * * *"},
+	}
+
+	if err = s.Save(r); err != nil {
+		t.Fatal(err)
+	}
+	r.LastRun = lastRun
+	if err = s.Save(r); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Save(ticker.JobRecord{Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Delete("other"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Save(ticker.JobRecord{Name: "other"}); err != nil {
+		t.Fatalf("expected deleted name to be reusable: %v", err)
+	}
+	if err = s.Delete("other"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "report" || list[0].Schedule.Cron != "0 2 * * *" || !list[0].LastRun.Equal(lastRun) {
+		t.Fatalf("unexpected records %+v", list)
+	}
+}
@@ -0,0 +1,467 @@
+package ticker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JobStore persists named tasks so they survive restarts
+type JobStore interface {
+	Load() ([]JobRecord, error)
+	Save(r JobRecord) error
+	Delete(name string) error
+}
+
+// JobRecord is the saved state of a named task
+type JobRecord struct {
+	Name     string    `json:"name"`
+	Schedule Schedule  `json:"schedule"`
+	LastRun  time.Time `json:"last_run"`
+}
+
+// Schedule is the serializable form of a TaskConfig's schedule
+type Schedule struct {
+	Cron     string         `json:"cron,omitempty"`
+	Unit     time.Duration  `json:"unit,omitempty"`
+	Interval int            `json:"interval,omitempty"`
+	Weekday  time.Weekday   `json:"weekday"`
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	Day      int            `json:"day,omitempty"`
+	Nth      int            `json:"nth,omitempty"`
+	NthDay   time.Weekday   `json:"nth_day,omitempty"`
+	Month    time.Month     `json:"month,omitempty"`
+	Hour     int            `json:"hour"`
+	Minute   int            `json:"minute"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Once     bool           `json:"once,omitempty"`
+	Location string         `json:"location,omitempty"`
+	Between  []string       `json:"between,omitempty"`
+	Except   []string       `json:"except,omitempty"`
+	Trigger  bool           `json:"trigger,omitempty"` // runs on triggers too
+	Aligned  bool           `json:"aligned,omitempty"`
+	Offset   time.Duration  `json:"offset,omitempty"`
+	Jitter   time.Duration  `json:"jitter,omitempty"`
+}
+
+// MissedRunPolicy decides what happens to the runs of a restored task that should have happened while it was down
+type MissedRunPolicy int
+
+const (
+	// MissedRunOnce runs the task once if any of its runs were missed
+	MissedRunOnce MissedRunPolicy = iota
+	// MissedRunAll runs the task once for each missed run
+	MissedRunAll
+	// MissedRunSkip ignores missed runs and waits for the next one
+	MissedRunSkip
+)
+
+// maxMissedRuns caps the number of missed runs that are counted for MissedRunAll
+const maxMissedRuns = 1000
+
+func (t *TaskConfig) schedule() (s Schedule) {
+	s = Schedule{
+		Unit:     t.unit,
+		Interval: int(t.interval),
+		Weekday:  t.weekDay,
+		Day:      t.monthDay,
+		Nth:      t.nth,
+		NthDay:   t.nthDay,
+		Month:    t.month,
+		Hour:     t.hour,
+		Minute:   t.minute,
+		From:     t.from,
+		To:       t.to,
+		Once:     t.oneShot,
+		Trigger:  t.triggered,
+		Aligned:  t.aligned,
+		Offset:   t.offset,
+		Jitter:   t.jitter,
+	}
+	if t.cron != nil {
+		s.Cron = t.cron.expr
+	}
+	if t.loc != nil {
+		s.Location = t.loc.String()
+	}
+	for _, w := range t.windows {
+		s.Between = append(s.Between, w.String())
+	}
+	s.Except = append(s.Except, t.blackouts...)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if t.weekDays&(1<<uint(d)) != 0 {
+			s.Weekdays = append(s.Weekdays, d)
+		}
+	}
+
+	return
+}
+
+// setSchedule replaces the schedule of t with s. t doesn't change if s is invalid.
+func (t *TaskConfig) setSchedule(s Schedule) (err error) {
+	var cron *cronSchedule
+	if s.Cron != "" {
+		if cron, err = parseCron(s.Cron); err != nil {
+			return
+		}
+	}
+
+	var loc *time.Location
+	if s.Location != "" {
+		if loc, err = time.LoadLocation(s.Location); err != nil {
+			return
+		}
+	}
+
+	var windows []window
+	for _, b := range s.Between {
+		w, err := parseWindow(b)
+		if err != nil {
+			return err
+		}
+		windows = append(windows, w)
+	}
+
+	t.cron = cron
+	t.loc = loc
+	t.windows = windows
+	t.blackouts = append([]string(nil), s.Except...)
+
+	t.unit = s.Unit
+	t.interval = time.Duration(s.Interval)
+	t.weekDay = s.Weekday
+	t.weekDays = 0
+	for _, d := range s.Weekdays {
+		t.weekDays |= 1 << uint(d)
+	}
+	t.monthDay = s.Day
+	t.nth = s.Nth
+	t.nthDay = s.NthDay
+	t.month = s.Month
+	t.hour = s.Hour
+	t.minute = s.Minute
+	t.from = s.From
+	t.to = s.To
+	t.oneShot = s.Once
+	t.triggered = s.Trigger
+	t.aligned = s.Aligned
+	t.offset = s.Offset
+	t.jitter = s.Jitter
+
+	return
+}
+
+// missedRuns counts the runs that should have happened after lastRun up to now
+func (t *TaskConfig) missedRuns(lastRun, now time.Time) (n int) {
+	if !t.timed() {
+		return 0
+	}
+
+	for step := t.nextRun(time.Time{}, lastRun); !step.IsZero() && !step.After(now); step = t.nextRun(step, step) {
+		if t.to.Year() != 1 && step.After(t.to) || n == maxMissedRuns {
+			break
+		}
+		if step.After(t.from) {
+			n++
+		}
+		if t.oneShot {
+			break
+		}
+	}
+
+	return
+}
+
+// WithStore saves named tasks to store and applies policy to the runs they missed when they're restored.
+func (s *Scheduler) WithStore(store JobStore, policy MissedRunPolicy) *Scheduler {
+	s.store = store
+	s.missedRunPolicy = policy
+	return s
+}
+
+// OnStoreError sets a function that receives the errors of saving tasks to the store, the history sink and the locker.
+// It's called outside of the scheduler's lock, so it can use the scheduler.
+func (s *Scheduler) OnStoreError(f func(error)) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.storeErrorHandler = f
+	return s
+}
+
+// Register registers the handler of the named tasks that are restored from the store
+func (s *Scheduler) Register(name string, f TaskFunc) *Scheduler {
+	return s.RegisterContext(name, f.withContext())
+}
+
+// RegisterContext is like Register for handlers that take a context and return an error
+func (s *Scheduler) RegisterContext(name string, f ContextTaskFunc) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.handlers[name] = f
+	return s
+}
+
+// Restore loads the tasks of the store and runs the ones with a registered handler.
+// Tasks which are configured by code with the same name don't run while a restored task is running.
+func (s *Scheduler) Restore() error {
+	if err := s.loadRecords(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for name, r := range s.records {
+		f, ok := s.handlers[name]
+		if !ok {
+			continue
+		}
+
+		t := s.Every().Name(name)
+		if err := t.setSchedule(r.Schedule); err != nil {
+			return err
+		}
+
+		go t.DoContext(f, nil)
+	}
+
+	return nil
+}
+
+// loadRecords loads the records of the store once. Scheduler must not be locked.
+func (s *Scheduler) loadRecords() error {
+	s.lock.Lock()
+	store, loaded := s.store, s.records != nil
+	s.lock.Unlock()
+
+	if store == nil || loaded {
+		return nil
+	}
+
+	list, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.records == nil {
+		s.records = make(map[string]JobRecord, len(list))
+		for _, r := range list {
+			s.records[r.Name] = r
+		}
+	}
+
+	return nil
+}
+
+// restore applies the saved state of a named task. Scheduler must be locked.
+func (s *Scheduler) restore(t *TaskConfig) {
+	r, ok := s.records[t.name]
+	if !ok || r.LastRun.IsZero() {
+		return
+	}
+
+	t.lastRun = r.LastRun
+	switch missed := t.missedRuns(r.LastRun, s.clock.Now()); {
+	case missed == 0:
+	case s.missedRunPolicy == MissedRunSkip:
+		s.missedLocked(t, r.LastRun, missed, MissedDowntime)
+	case s.missedRunPolicy == MissedRunOnce:
+		t.catchUp = 1
+		s.missedLocked(t, r.LastRun, missed-1, MissedDowntime)
+	case s.missedRunPolicy == MissedRunAll:
+		t.catchUp = missed
+	}
+}
+
+// storeWrite is a write to the store that was queued while the scheduler was locked
+type storeWrite struct {
+	record JobRecord
+	delete bool // deletes the record named record.Name instead of saving record
+}
+
+// saveLocked queues writing the state of a named task to the store. Scheduler must be locked.
+func (s *Scheduler) saveLocked(t *TaskConfig) {
+	if s.store == nil || t.name == "" || t.forgotten {
+		return
+	}
+
+	s.writes = append(s.writes, storeWrite{record: JobRecord{
+		Name:     t.name,
+		Schedule: t.schedule(),
+		LastRun:  t.lastRun,
+	}})
+}
+
+// forget deletes a named task from the store. Scheduler must not be locked.
+func (s *Scheduler) forget(t *TaskConfig) {
+	s.lock.Lock()
+	s.forgetLocked(t)
+	s.lock.Unlock()
+
+	s.flushWrites()
+}
+
+// forgetLocked queues deleting a named task from the store. Scheduler must be locked.
+func (s *Scheduler) forgetLocked(t *TaskConfig) {
+	if s.store == nil || t.name == "" || t.forgotten {
+		return
+	}
+
+	t.forgotten = true
+	s.writes = append(s.writes, storeWrite{record: JobRecord{Name: t.name}, delete: true})
+}
+
+// flushWrites writes the records that were queued while the scheduler was locked.
+// Scheduler must not be locked.
+func (s *Scheduler) flushWrites() {
+	s.lock.Lock()
+	n := len(s.writes)
+	s.lock.Unlock()
+	if n == 0 {
+		return
+	}
+
+	// the queue is taken while holding storeLock so that writes of parallel flushes don't overtake each other
+	s.storeLock.Lock()
+	s.lock.Lock()
+	writes, store := s.writes, s.store
+	s.writes = nil
+	s.lock.Unlock()
+
+	var errs []error
+	for _, w := range writes {
+		var err error
+		if w.delete {
+			err = store.Delete(w.record.Name)
+		} else {
+			err = store.Save(w.record)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.storeLock.Unlock()
+
+	for _, err := range errs {
+		s.storeError(err)
+	}
+}
+
+// storeError passes err to the store error handler. Scheduler must not be locked, so the handler can use it.
+func (s *Scheduler) storeError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.lock.Lock()
+	handler := s.storeErrorHandler
+	s.lock.Unlock()
+
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// FileStore is a JobStore that keeps all records in a JSON file
+type FileStore struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Load() (list []JobRecord, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	records, err := f.read()
+	if err != nil {
+		return
+	}
+
+	list = make([]JobRecord, 0, len(records))
+	for _, r := range records {
+		list = append(list, r)
+	}
+
+	return
+}
+
+func (f *FileStore) Save(r JobRecord) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	records, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	records[r.Name] = r
+	return f.write(records)
+}
+
+func (f *FileStore) Delete(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	records, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := records[name]; !ok {
+		return nil
+	}
+
+	delete(records, name)
+	return f.write(records)
+}
+
+func (f *FileStore) read() (records map[string]JobRecord, err error) {
+	records = make(map[string]JobRecord)
+
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return records, nil
+	} else if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &records)
+	return
+}
+
+// write replaces the file through a temporary file so a crash won't leave a partially written file
+func (f *FileStore) write(records map[string]JobRecord) error {
+	data, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,223 @@
+package ticker
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func tempStore(t *testing.T) (store *FileStore, cleanup func()) {
+	dir, err := ioutil.TempDir("", "ticker")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewFileStore(filepath.Join(dir, "jobs.json")), func() { os.RemoveAll(dir) }
+}
+
+func TestFileStore(t *testing.T) {
+	store, cleanup := tempStore(t)
+	defer cleanup()
+
+	list, err := store.Load()
+	if err != nil || len(list) != 0 {
+		t.Fatalf("expected empty store, got %v %v", list, err)
+	}
+
+	r := JobRecord{
+		Name:     "report",
+		Schedule: Schedule{Cron: "0 2 * * *"},
+		LastRun:  date(t, "2020-03-10 02:00"),
+	}
+	if err = store.Save(r); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Save(JobRecord{Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Delete("other"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != r.Name || !reflect.DeepEqual(list[0].Schedule, r.Schedule) || !list[0].LastRun.Equal(r.LastRun) {
+		t.Fatalf("expected %v, got %v", r, list)
+	}
+}
+
+func TestScheduler_Restore(t *testing.T) {
+	tests := []struct {
+		policy MissedRunPolicy
+		runs   int
+	}{
+		{MissedRunOnce, 1},
+		{MissedRunAll, 3},
+		{MissedRunSkip, 0},
+	}
+
+	for _, test := range tests {
+		store, cleanup := tempStore(t)
+
+		// the task ran on 7th and missed 8th, 9th and 10th
+		store.Save(JobRecord{
+			Name:     "nightly",
+			Schedule: Schedule{Unit: unitDays, Interval: 1, Hour: 2},
+			LastRun:  date(t, "2020-03-07 02:00"),
+		})
+
+		clock := NewFakeClock(date(t, "2020-03-10 12:00"))
+		s := NewScheduler(10).WithClock(clock).WithStore(store, test.policy)
+		s.Start()
+
+		runs := make(chan time.Time, 10)
+		s.Register("nightly", func(*Task) { runs <- clock.Now() })
+		if err := s.Restore(); err != nil {
+			t.Fatal(err)
+		}
+
+		n := 0
+	wait:
+		for {
+			select {
+			case <-runs:
+				n++
+			case <-time.After(50 * time.Millisecond):
+				break wait
+			}
+		}
+		if n != test.runs {
+			t.Errorf("policy %d: expected %d runs, got %d", test.policy, test.runs, n)
+		}
+
+		// back to the normal schedule
+		clock.BlockUntil(1)
+		clock.Advance(date(t, "2020-03-11 02:00").Sub(clock.Now()))
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Errorf("policy %d: expected the scheduled run", test.policy)
+		}
+
+		s.Stop()
+
+		list, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || !list[0].LastRun.Equal(date(t, "2020-03-11 02:00")) {
+			t.Errorf("policy %d: expected last run to be saved, got %v", test.policy, list)
+		}
+
+		cleanup()
+	}
+}
+
+func TestScheduler_RestoreCatchUp(t *testing.T) {
+	for _, policy := range []MissedRunPolicy{MissedRunOnce, MissedRunAll} {
+		store, cleanup := tempStore(t)
+
+		// the task missed the run of the 9th
+		store.Save(JobRecord{
+			Name:     "nightly",
+			Schedule: Schedule{Unit: unitDays, Interval: 1, Hour: 23},
+			LastRun:  date(t, "2020-03-08 23:00"),
+		})
+
+		clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+		s := NewScheduler(10).WithClock(clock).WithStore(store, policy)
+		s.Start()
+
+		runs := make(chan time.Time, 10)
+		s.Register("nightly", func(*Task) { runs <- clock.Now() })
+		if err := s.Restore(); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("policy %d: expected the catch-up run", policy)
+		}
+
+		// the catch-up run doesn't move the schedule
+		var next time.Time
+		for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+			if tasks := s.Tasks(); len(tasks) == 1 && tasks[0].Runs == 1 && !tasks[0].NextRun.IsZero() {
+				next = tasks[0].NextRun
+				break
+			}
+		}
+		if !next.Equal(date(t, "2020-03-10 23:00")) {
+			t.Errorf("policy %d: expected the next run at 2020-03-10 23:00, got %s", policy, next)
+		}
+
+		s.Stop()
+		cleanup()
+	}
+}
+
+func TestScheduler_NamedTaskIsSaved(t *testing.T) {
+	store, cleanup := tempStore(t)
+	defer cleanup()
+
+	r := startFake(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		s.WithStore(store, MissedRunOnce)
+		return s.Every(10).Minutes().Name("poll").Once()
+	})
+	defer r.s.Stop()
+
+	// the store is written after the scheduler is unlocked
+	var list []JobRecord
+	r.waitFor("the task to be saved", func() bool {
+		list, _ = store.Load()
+		return len(list) == 1
+	})
+	if list[0].Schedule.Unit != unitMinutes || list[0].Schedule.Interval != 10 {
+		t.Fatalf("expected task to be saved, got %v", list)
+	}
+
+	r.advanceTo("2020-03-10 00:10", true)
+	r.expectDone()
+
+	// finished tasks are deleted
+	r.waitFor("the finished task to be deleted", func() bool {
+		list, _ = store.Load()
+		return len(list) == 0
+	})
+}
+
+// failingStore is a JobStore whose writes fail
+type failingStore struct{}
+
+func (failingStore) Load() ([]JobRecord, error) { return nil, nil }
+func (failingStore) Save(JobRecord) error       { return errors.New("disk full") }
+func (failingStore) Delete(string) error        { return errors.New("disk full") }
+
+func TestScheduler_StoreErrorUsesScheduler(t *testing.T) {
+	failures := make(chan int, 10)
+	r := startFake(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		// the handler is called outside of the scheduler's lock
+		s.WithStore(failingStore{}, MissedRunOnce).OnStoreError(func(error) { failures <- len(s.Tasks()) })
+		return s.Every().Minute().Name("poll")
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 00:01", true)
+	for i := 0; i < 2; i++ {
+		select {
+		case n := <-failures:
+			if n != 1 {
+				t.Fatalf("expected 1 task, got %d", n)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the errors of saving the task when it's added and after it runs")
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ticker
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -9,6 +10,21 @@ import (
 
 type TaskFunc func(*Task)
 
+// ContextTaskFunc is a handler that can be cancelled through ctx and reports failures by returning an error.
+// ctx is cancelled when the run times out, the task is stopped or the scheduler is stopped.
+type ContextTaskFunc func(ctx context.Context, t *Task) error
+
+func (f TaskFunc) withContext() ContextTaskFunc {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, t *Task) error {
+		f(t)
+		return nil
+	}
+}
+
 const (
 	unitSeconds = time.Second
 	unitMinutes = time.Minute
@@ -18,103 +34,207 @@ const (
 )
 
 type TaskConfig struct {
+	scheduler *Scheduler
+
 	id       uuid.UUID
-	handler  TaskFunc
+	handler  ContextTaskFunc
+	timeout  time.Duration
+	retry    *RetryPolicy
 	oneShot  bool
 	nextStep time.Time // is the nextStep time for this task to run
 	lastRun  time.Time
 
 	unit         time.Duration // unit of interval (hours, days or what)
 	interval     time.Duration // number of units to repeat (every 3 seconds, the 3 is interval)
-	weekDay      time.Weekday
+	weekDay      time.Weekday  // day of weekly tasks if weekDays is empty
+	weekDays     uint8         // set of days of weekly tasks
+	monthDay     int           // day of monthly and yearly tasks or lastDay
+	nth          int           // if not zero, monthly and yearly tasks run on the nth nthDay of the month
+	nthDay       time.Weekday
+	month        time.Month // month of yearly tasks
+	windows      []window   // daily windows the task can run in
+	blackouts    []string   // names of the scheduler's blackouts the task can't run in
 	hour, minute int
 	from, to     time.Time
-
-	task       *Task
-	shouldStop chan struct{}
+	cron         *cronSchedule
+	loc          *time.Location
+	aligned      bool          // intervals run at the boundaries of the clock
+	offset       time.Duration // of aligned intervals
+	jitter       time.Duration
+
+	name        string
+	catchUp     int // number of missed runs to run right away
+	overlap     OverlapPolicy
+	parallel    int
+	hooks       []Hooks
+	maxPanics   int
+	historySize int
+	triggered   bool // runs when it's triggered too
+	debounce    time.Duration
+	throttle    time.Duration
+	triggers    <-chan struct{}
+
+	// state of a running task, guarded by the scheduler's lock
+	task        *Task
+	ctx         context.Context // parent of the contexts of runs
+	cancelRuns  context.CancelFunc
+	slot        *entry        // next run of the schedule
+	trigger     *entry        // triggered run that is pending or waiting for the running one
+	triggeredAt time.Time     // start of the last triggered run
+	pending     []*entry      // all entries of the task in the scheduler's queue
+	queued      []*entry      // due runs waiting for the running one
+	running     int           // number of running handlers
+	slotsRun    int           // number of schedule runs that have started
+	ended       bool          // schedule has no more runs
+	paused      bool          // schedule runs aren't queued until the task is resumed
+	panics      int           // number of panics since the task was resumed
+	history     []RunRecord   // ring of the last runs
+	historyNext int           // index of the oldest run in history once it's full
+	removed     bool          // task won't be scheduled again
+	forgotten   bool          // task is deleted from the store
+	idle        chan struct{} // closed when the task is removed and none of its handlers are running
+	done        chan struct{} // closed when the task finishes by its schedule
+	shouldStop  chan struct{}
 }
 
 type Task struct {
-	config  *TaskConfig
-	Payload interface{}
-	Elapsed time.Duration
+	config   *TaskConfig
+	Payload  interface{}
+	Elapsed  time.Duration
+	Err      error                  // error of the last run
+	Failures int                    // number of failed runs
+	Attempt  int                    // attempt number of the current run, starting from 1. It's more than 1 for retries
+	Skipped  int                    // number of runs skipped by the overlap policy
+	Runs     int                    // number of finished runs
+	Panics   int                    // number of runs that panicked. They're also counted as failures
+	Parents  map[string]interface{} // payloads of the parent nodes when the task is a node of a Workflow
 }
 
 func (t *Task) Id() uuid.UUID { return t.config.id }
 
+// Config holds the default limits of schedulers. Changes affect the schedulers created afterwards, including the default
+// scheduler if they're made before it's first used.
 var Config = struct {
-	MaxTasks   int
+	MaxTasks     int
+	Workers      int           // number of handlers that can run at the same time
+	DrainTimeout time.Duration // how long stopping waits for running handlers
+
+	// Deprecated: TaskWaitUs does nothing. Tasks used to poll their next run at this interval, but the dispatcher
+	// now sleeps until the earliest run is due.
 	TaskWaitUs time.Duration
 }{
-	MaxTasks:   65 * 1000,
-	TaskWaitUs: 1,
+	MaxTasks:     65 * 1000,
+	Workers:      64,
+	DrainTimeout: 30 * time.Second,
+	TaskWaitUs:   1,
 }
 
 var (
-	openTasks  = make(chan struct{}, Config.MaxTasks)
-	stopSignal = make(chan struct{}, Config.MaxTasks)
-	tWaitGroup = sync.WaitGroup{}
-	local      = time.Local
+	defaultOnce      sync.Once
+	defaultScheduler *Scheduler
 )
 
-// Stop stops processing all tasks. It **MUST** be called whenever the program finishes so tasks will be saved.
-func Stop() {
-	for i := 0; i < len(openTasks); i++ {
-		stopSignal <- struct{}{}
-	}
-	Wait()
+// Default returns the scheduler used by package level functions. It's created and started by the first call.
+func Default() *Scheduler {
+	defaultOnce.Do(func() {
+		defaultScheduler = NewScheduler(Config.MaxTasks)
+		defaultScheduler.Start()
+	})
+	return defaultScheduler
 }
 
-func Wait() { tWaitGroup.Wait() }
+// Stop stops processing all tasks of the default scheduler.
+// It **MUST** be called whenever the program finishes so tasks will be saved.
+func Stop() { Default().Stop() }
+
+func Wait() { Default().Wait() }
+
+func NumTasks() int { return Default().NumTasks() }
+
+// Every begins configuring a task on the default scheduler. Supply zero or one intervals. No intervals will be counted as 1
+func Every(interval ...int) *TaskConfig { return Default().Every(interval...) }
 
-func NumTasks() int { return len(openTasks) }
+// Cron begins configuring a task on the default scheduler from a cron expression. See Scheduler.Cron
+func Cron(expr string) (*TaskConfig, error) { return Default().Cron(expr) }
 
-// Every begins configuring a task. Supply zero or one intervals. No intervals will be counted as 1
-func Every(interval ...int) *TaskConfig {
-	i := 1
-	if len(interval) > 0 {
-		i = interval[0]
+// advance returns the time of the next run of the schedule or zero time if there's none. catchUp is true if it's a
+// run that was missed while the task was down, which isn't part of the schedule.
+func (t *TaskConfig) advance(now time.Time) (next time.Time, catchUp bool) {
+	if t.oneShot && t.slotsRun > 0 {
+		return time.Time{}, false
 	}
 
-	now := time.Now()
-	return &TaskConfig{
-		oneShot:  false,
-		lastRun:  now,
-		interval: time.Duration(i),
-		weekDay:  now.Weekday(),
-		hour:     now.Hour(),
-		minute:   now.Minute(),
+	if t.catchUp > 0 {
+		t.catchUp--
+		return now, true
 	}
+
+	return t.nextRun(t.nextStep, now), false
 }
 
-func (t *TaskConfig) calculateNextRun() {
-	if t.unit == unitWeeks {
-		now := time.Now()
-		remainingDays := t.weekDay - now.Weekday()
-		if remainingDays <= 0 {
-			// schedule for nextStep week
-			t.nextStep = now.AddDate(0, 0, 6-int(now.Weekday())+int(t.weekDay)+1)
+// nextRun returns the first run of the schedule after now. prev is the previous scheduled run or zero time.
+func (t *TaskConfig) nextRun(prev, now time.Time) (next time.Time) {
+	loc := t.location(now)
+	now = now.In(loc)
+
+	if t.cron != nil {
+		next = t.cron.next(now)
+	} else if t.unit == unitWeeks {
+		next = t.nextWeekly(prev, now, loc)
+	} else if t.unit == unitMonths || t.unit == unitYears {
+		next = t.nextMonthly(prev, now, loc)
+	} else if t.unit == unitDays {
+		if prev.IsZero() {
+			// first run is the closest hour:minute
+			next = wallTime(now.Year(), now.Month(), now.Day(), t.hour, t.minute, 0, loc)
+			if !next.After(now) {
+				next = wallTime(now.Year(), now.Month(), now.Day()+1, t.hour, t.minute, 0, loc)
+			}
 		} else {
-			t.nextStep = now.AddDate(0, 0, int(remainingDays))
+			next = prev.In(loc)
 		}
-
-		t.nextStep = time.Date(t.nextStep.Year(), t.nextStep.Month(), t.nextStep.Day(), t.hour, t.minute, 0, 0, local)
-		t.nextStep = t.nextStep.Add((t.interval - 1) * t.unit)
-	} else if t.unit == unitDays {
-		t.nextStep = t.nextStep.Add(t.interval * t.unit)
-		t.nextStep = time.Date(t.nextStep.Year(), t.nextStep.Month(), t.nextStep.Day(), t.hour, t.minute, 0, 0, local)
+		// skip the days that are already passed
+		for !next.After(now) {
+			next = wallTime(next.Year(), next.Month(), next.Day()+int(t.interval), t.hour, t.minute, 0, loc)
+		}
+	} else if t.aligned {
+		next = t.nextAligned(now, loc)
 	} else {
-		t.nextStep = time.Now().Add(t.interval * t.unit)
+		next = now.Add(t.interval * t.unit)
 	}
+
+	return
 }
 
+// Stop stops the task. If it's running, its context is cancelled and Stop waits for it up to the drain timeout.
+// Handlers should return instead of calling Stop of their own task.
 func (t *TaskConfig) Stop() {
-	t.shouldStop <- struct{}{}
+	if done := t.stop(); done != nil {
+		t.scheduler.drain(done)
+	}
+}
+
+// stop stops the task without waiting for its runs. It returns a channel that's closed when they return or nil if
+// the task isn't running.
+func (t *TaskConfig) stop() <-chan struct{} {
+	select {
+	case t.shouldStop <- struct{}{}:
+	default:
+	}
+
+	return t.scheduler.cancel(t)
 }
 
+// Do starts running f by the schedule and blocks until the task is finished or stopped
 func (t *TaskConfig) Do(f TaskFunc, payload interface{}) (r *TaskConfig) {
-	tWaitGroup.Add(1)
-	defer tWaitGroup.Done()
+	return t.DoContext(f.withContext(), payload)
+}
+
+// DoContext is like Do for handlers that take a context and return an error
+func (t *TaskConfig) DoContext(f ContextTaskFunc, payload interface{}) (r *TaskConfig) {
+	s := t.scheduler
+	s.waitGroup.Add(1)
+	defer s.waitGroup.Done()
 
 	r = t
 	t.handler = f
@@ -124,43 +244,51 @@ func (t *TaskConfig) Do(f TaskFunc, payload interface{}) (r *TaskConfig) {
 	}
 
 	t.id, _ = uuid.NewRandom()
-	t.calculateNextRun()
-
-	openTasks <- struct{}{}
-	ticker := time.NewTicker(Config.TaskWaitUs * time.Microsecond)
-	for {
-		select {
-		case <-ticker.C:
-			if time.Since(t.nextStep) > 0 {
-				if time.Now().After(t.from) {
-					t.task.Elapsed = time.Since(t.lastRun)
-					if t.handler != nil {
-						t.handler(t.task)
-					}
-					t.lastRun = time.Now()
-					if t.oneShot {
-						<-openTasks
-						return
-					}
-				}
-				t.calculateNextRun()
-
-				if t.to.Year() != 1 && t.nextStep.After(t.to) {
-					<-openTasks
-					return
-				}
-			}
-		case <-stopSignal:
-			return
-		case <-t.shouldStop:
-			<-openTasks
-			return
-		}
+
+	started, stopped := s.signals()
+	select {
+	case <-started:
+	case <-stopped:
+		return
+	case <-t.shouldStop:
+		return
+	}
+
+	select {
+	case s.openTasks <- struct{}{}:
+	case <-stopped:
+		return
+	case <-t.shouldStop:
+		return
+	}
+	defer func() { <-s.openTasks }()
+
+	added := s.add(t)
+	s.flushEvents()
+	if !added {
+		return
+	}
+
+	if t.triggers != nil {
+		quit := make(chan struct{})
+		defer close(quit)
+		go s.listen(t, quit)
+	}
+
+	select {
+	case <-t.done:
+	case <-stopped:
+		s.remove(t)
+	case <-t.shouldStop:
+		s.remove(t)
+		s.forget(t)
 	}
+
+	return
 }
 
 func (t *TaskConfig) Then(f TaskFunc) *TaskConfig {
-	t.task.Elapsed = time.Since(t.lastRun)
+	t.task.Elapsed = t.scheduler.clock.Since(t.lastRun)
 	if f != nil {
 		f(t.task)
 	}
@@ -173,6 +301,18 @@ func (t *TaskConfig) At(hour, minute int) *TaskConfig {
 	return t
 }
 
+// Name names the task. Named tasks are saved to the scheduler's store and only one task with a name can run at a time.
+func (t *TaskConfig) Name(name string) *TaskConfig {
+	t.name = name
+	return t
+}
+
+// Timeout cancels the context of each run after d
+func (t *TaskConfig) Timeout(d time.Duration) *TaskConfig {
+	t.timeout = d
+	return t
+}
+
 func (t *TaskConfig) Once() *TaskConfig {
 	t.oneShot = true
 	return t
@@ -218,44 +358,16 @@ func (t *TaskConfig) Weeks() *TaskConfig {
 	return t
 }
 
-func (t *TaskConfig) Saturday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Saturday
-	return t
-}
+func (t *TaskConfig) Saturday() *TaskConfig { return t.On(time.Saturday) }
 
-func (t *TaskConfig) Sunday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Sunday
-	return t
-}
+func (t *TaskConfig) Sunday() *TaskConfig { return t.On(time.Sunday) }
 
-func (t *TaskConfig) Monday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Monday
-	return t
-}
+func (t *TaskConfig) Monday() *TaskConfig { return t.On(time.Monday) }
 
-func (t *TaskConfig) Tuesday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Tuesday
-	return t
-}
+func (t *TaskConfig) Tuesday() *TaskConfig { return t.On(time.Tuesday) }
 
-func (t *TaskConfig) Wednesday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Wednesday
-	return t
-}
+func (t *TaskConfig) Wednesday() *TaskConfig { return t.On(time.Wednesday) }
 
-func (t *TaskConfig) Thursday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Thursday
-	return t
-}
+func (t *TaskConfig) Thursday() *TaskConfig { return t.On(time.Thursday) }
 
-func (t *TaskConfig) Friday() *TaskConfig {
-	t.unit = unitWeeks
-	t.weekDay = time.Friday
-	return t
-}
+func (t *TaskConfig) Friday() *TaskConfig { return t.On(time.Friday) }
@@ -0,0 +1,335 @@
+package ticker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, s string) time.Time {
+	d, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// fakeRun runs a task configured by config on a scheduler with a fake clock and reports the time of each run
+type fakeRun struct {
+	t       *testing.T
+	s       *Scheduler
+	clock   *FakeClock
+	config  *TaskConfig
+	runs    chan time.Time
+	release chan struct{} // lets a run of blockingHandler return
+	done    chan struct{}
+
+	lock    sync.Mutex
+	n       int // number of runs that started
+	blocked int // number of runs blocked in the handler by block
+}
+
+// fakeHandler is called by each run of a fakeRun after it's reported. n is the number of the run, starting from 1.
+type fakeHandler func(r *fakeRun, ctx context.Context, n int) error
+
+// blockingHandler blocks until the run is released or cancelled
+func blockingHandler(r *fakeRun, ctx context.Context, n int) error {
+	r.block(ctx, r.release)
+	return nil
+}
+
+// block blocks a run until ctx is done or release receives. Blocked runs are counted, so settle doesn't wait for them.
+func (r *fakeRun) block(ctx context.Context, release <-chan struct{}) {
+	r.lock.Lock()
+	r.blocked++
+	r.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-release:
+	}
+
+	r.lock.Lock()
+	r.blocked--
+	r.lock.Unlock()
+}
+
+func startFake(t *testing.T, now string, config func(s *Scheduler) *TaskConfig) *fakeRun {
+	return startFakeHandler(t, now, config, nil)
+}
+
+// startFakeHandler is like startFake for a task with handler. The scheduler is started after config is called, so
+// config can set up the scheduler too.
+func startFakeHandler(t *testing.T, now string, config func(s *Scheduler) *TaskConfig, handler fakeHandler) *fakeRun {
+	r := &fakeRun{
+		t:       t,
+		clock:   NewFakeClock(date(t, now)),
+		runs:    make(chan time.Time, 10),
+		release: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	r.s = NewScheduler(10).WithClock(r.clock)
+	r.config = config(r.s)
+	r.s.Start()
+
+	go func() {
+		defer close(r.done)
+		r.config.DoContext(func(ctx context.Context, task *Task) error {
+			r.lock.Lock()
+			r.n++
+			n := r.n
+			r.lock.Unlock()
+
+			r.runs <- r.clock.Now()
+			if handler == nil {
+				return nil
+			}
+			return handler(r, ctx, n)
+		}, nil)
+	}()
+
+	r.waitFor("the task to be added", func() bool {
+		r.s.lock.Lock()
+		defer r.s.lock.Unlock()
+		return r.config.done != nil
+	})
+
+	return r
+}
+
+// waitFor waits up to a second for cond to become true
+func (r *fakeRun) waitFor(what string, cond func() bool) {
+	r.t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			r.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// advance waits for the dispatcher to be ready for the next run, moves the clock by d and waits for it to handle the
+// runs that became due
+func (r *fakeRun) advance(d time.Duration) {
+	r.t.Helper()
+
+	r.waitArmed()
+	r.clock.Advance(d)
+	r.settle()
+}
+
+// advanceTo moves the clock to at and expects the task to run or not
+func (r *fakeRun) advanceTo(at string, expectRun bool) {
+	r.t.Helper()
+
+	r.advance(date(r.t, at).Sub(r.clock.Now()))
+	if expectRun {
+		r.expectRunAt(date(r.t, at))
+	} else {
+		r.expectNoRun()
+	}
+}
+
+// expectRunAt expects the next run to have started at at
+func (r *fakeRun) expectRunAt(at time.Time) {
+	r.t.Helper()
+
+	select {
+	case ran := <-r.runs:
+		if !ran.Equal(at) {
+			r.t.Fatalf("expected run at %s, ran at %s", at, ran)
+		}
+	case <-time.After(time.Second):
+		r.t.Fatalf("expected run at %s", at)
+	}
+	r.settle()
+}
+
+// expectRuns expects exactly n runs to have started
+func (r *fakeRun) expectRuns(n int) {
+	r.t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.runs:
+		case <-time.After(time.Second):
+			r.t.Fatalf("expected %d runs to start, got %d", n, i)
+		}
+	}
+	r.expectNoRun()
+}
+
+func (r *fakeRun) expectNoRun() {
+	r.t.Helper()
+
+	r.settle()
+	select {
+	case ran := <-r.runs:
+		r.t.Fatalf("unexpected run at %s", ran)
+	default:
+	}
+}
+
+// waitArmed waits for the dispatcher to arm its timer for the earliest queued run, so advancing the clock fires it
+func (r *fakeRun) waitArmed() {
+	r.t.Helper()
+
+	r.waitFor("the dispatcher to arm its timer", func() bool {
+		r.s.lock.Lock()
+		var next time.Time
+		if len(r.s.queue) > 0 {
+			next = r.s.queue[0].at
+		}
+		r.s.lock.Unlock()
+
+		return next.IsZero() || r.clock.armedAt(next)
+	})
+}
+
+// armedAt returns true if a timer of the clock is armed to fire at at
+func (c *FakeClock) armedAt(at time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for t := range c.timers {
+		if t.when.Equal(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// settle waits until the dispatcher handled the runs that are due and each of them returned, is blocked or is waiting
+// for a blocked worker. Runs that returned have scheduled the next one by then.
+func (r *fakeRun) settle() {
+	r.t.Helper()
+
+	r.waitFor("the scheduler to settle", func() bool {
+		r.s.lock.Lock()
+		defer r.s.lock.Unlock()
+		if len(r.s.queue) > 0 && !r.s.queue[0].at.After(r.clock.Now()) {
+			return false
+		}
+
+		r.lock.Lock()
+		blocked := r.blocked
+		r.lock.Unlock()
+		return r.config.running == blocked || blocked >= r.s.workers
+	})
+}
+
+// releaseOne lets a run of blockingHandler return and waits until the scheduler counts it as returned
+func (r *fakeRun) releaseOne() {
+	r.t.Helper()
+
+	r.s.lock.Lock()
+	running := r.config.running
+	r.s.lock.Unlock()
+
+	r.release <- struct{}{}
+	r.waitFor("the run to return", func() bool {
+		r.s.lock.Lock()
+		defer r.s.lock.Unlock()
+		return r.config.running < running
+	})
+}
+
+func (r *fakeRun) expectSkipped(n int) {
+	r.t.Helper()
+
+	r.s.lock.Lock()
+	defer r.s.lock.Unlock()
+	if r.config.task.Skipped != n {
+		r.t.Fatalf("expected %d skipped runs, got %d", n, r.config.task.Skipped)
+	}
+}
+
+func (r *fakeRun) expectDone() {
+	r.t.Helper()
+
+	select {
+	case <-r.done:
+	case <-time.After(time.Second):
+		r.t.Fatal("expected task to finish")
+	}
+}
+
+// stop releases the blocked runs and stops the scheduler
+func (r *fakeRun) stop() {
+	close(r.release)
+	r.s.Stop()
+}
+
+func TestTaskConfig_Weekly(t *testing.T) {
+	// 2020-03-09 is a monday
+	r := startFake(t, "2020-03-09 09:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Monday().At(10, 0)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-09 09:59", false)
+	r.advanceTo("2020-03-09 10:00", true)
+	r.advanceTo("2020-03-16 09:59", false)
+	r.advanceTo("2020-03-16 10:00", true)
+}
+
+func TestTaskConfig_EveryTwoWeeks(t *testing.T) {
+	r := startFake(t, "2020-03-09 09:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(2).Weeks().Wednesday().At(18, 30)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-11 18:30", false)
+	r.advanceTo("2020-03-18 18:29", false)
+	r.advanceTo("2020-03-18 18:30", true)
+	r.advanceTo("2020-03-25 18:30", false)
+	r.advanceTo("2020-04-01 18:30", true)
+}
+
+func TestTaskConfig_Daily(t *testing.T) {
+	r := startFake(t, "2020-03-10 12:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(2).Days().At(6, 30)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-11 06:29", false)
+	r.advanceTo("2020-03-11 06:30", true)
+	r.advanceTo("2020-03-12 06:30", false)
+	r.advanceTo("2020-03-13 06:30", true)
+	// runs that were missed while the clock jumped are skipped without losing the cadence
+	r.advanceTo("2020-03-20 06:30", true)
+	r.advanceTo("2020-03-21 06:30", true)
+	r.advanceTo("2020-03-22 06:30", false)
+	r.advanceTo("2020-03-23 06:30", true)
+}
+
+func TestTaskConfig_FromTo(t *testing.T) {
+	r := startFake(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Day().At(8, 0).
+			From(date(t, "2020-03-12 00:00")).
+			To(date(t, "2020-03-14 12:00"))
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 08:00", false)
+	r.advanceTo("2020-03-11 08:00", false)
+	r.advanceTo("2020-03-12 08:00", true)
+	r.advanceTo("2020-03-13 08:00", true)
+	r.advanceTo("2020-03-14 08:00", true)
+	r.expectDone()
+}
+
+func TestTaskConfig_Once(t *testing.T) {
+	r := startFake(t, "2020-03-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(90).Minutes().Once()
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 01:29", false)
+	r.advanceTo("2020-03-10 01:30", true)
+	r.expectDone()
+}
@@ -0,0 +1,138 @@
+package ticker
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotTriggered is returned by Scheduler.Trigger for tasks that don't run on triggers
+var ErrNotTriggered = errors.New("ticker: task doesn't run on triggers")
+
+// OnTrigger begins configuring a task that runs only when it's triggered by Scheduler.Trigger, TaskConfig.Trigger or
+// the channel set by TriggeredBy. Triggers that arrive while a run is waiting to start are merged into it.
+func (s *Scheduler) OnTrigger() *TaskConfig {
+	t := s.Every()
+	t.triggered = true
+	return t
+}
+
+// OnTrigger begins configuring a triggered task on the default scheduler. See Scheduler.OnTrigger
+func OnTrigger() *TaskConfig { return Default().OnTrigger() }
+
+// OrOnTrigger makes a task with a schedule run on triggers too. For schedules of an interval shorter than a day,
+// like Every(6).Hours(), each triggered run postpones the next scheduled one, so the task runs when it's triggered or
+// at least every interval.
+func (t *TaskConfig) OrOnTrigger() *TaskConfig {
+	t.triggered = true
+	return t
+}
+
+// Debounce waits until the task isn't triggered for d before running it
+func (t *TaskConfig) Debounce(d time.Duration) *TaskConfig {
+	t.debounce = d
+	return t
+}
+
+// Throttle runs the task at most once every d when it's triggered. Triggers that come sooner are merged into a run
+// at the end of the period.
+func (t *TaskConfig) Throttle(d time.Duration) *TaskConfig {
+	t.throttle = d
+	return t
+}
+
+// TriggeredBy triggers the task each time ch receives a value, until the task is stopped
+func (t *TaskConfig) TriggeredBy(ch <-chan struct{}) *TaskConfig {
+	t.triggered = true
+	t.triggers = ch
+	return t
+}
+
+// Trigger triggers the task. See Scheduler.Trigger.
+func (t *TaskConfig) Trigger() error { return t.scheduler.Trigger(t.id) }
+
+// Trigger queues a run of a triggered task, or moves its pending run if it's debounced. Triggers of a paused task
+// are ignored.
+func (s *Scheduler) Trigger(id uuid.UUID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+	if !t.triggered {
+		return ErrNotTriggered
+	}
+
+	s.triggerLocked(t)
+	return nil
+}
+
+// triggerLocked queues or moves the triggered run of t. Scheduler must be locked.
+func (s *Scheduler) triggerLocked(t *TaskConfig) {
+	if t.paused || t.removed {
+		return
+	}
+
+	at := s.clock.Now().Add(t.debounce)
+	if throttled := t.triggeredAt.Add(t.throttle); t.throttle > 0 && !t.triggeredAt.IsZero() && throttled.After(at) {
+		at = throttled
+	}
+
+	switch e := t.trigger; {
+	case e == nil:
+		t.trigger = &entry{task: t, at: at, kind: entryTrigger, attempt: 1}
+		s.push(t.trigger)
+	case e.index >= 0 && t.debounce > 0:
+		e.at = at
+		heap.Fix(&s.queue, e.index)
+		s.notify()
+	}
+}
+
+// timed reports whether t has a schedule besides its triggers
+func (t *TaskConfig) timed() bool {
+	return !t.triggered || t.unit != 0 || t.cron != nil
+}
+
+// startTriggered updates t when its triggered run e starts. Scheduler must be locked.
+func (s *Scheduler) startTriggered(t *TaskConfig, e *entry) {
+	if t.trigger == e {
+		t.trigger = nil
+	}
+	t.triggeredAt = s.clock.Now()
+
+	// the next scheduled run of an interval is counted from this run
+	if t.slot != nil && t.cron == nil && t.unit > 0 && t.unit < unitDays {
+		s.unqueueEntry(t.slot)
+		t.nextStep = time.Time{}
+		s.pushSlot(t)
+	}
+}
+
+// dropTriggered forgets e if it's the triggered run of t and it won't run. Scheduler must be locked.
+func (s *Scheduler) dropTriggered(t *TaskConfig, e *entry) {
+	if t.trigger == e {
+		t.trigger = nil
+	}
+}
+
+// listen triggers t each time its channel receives until quit is closed
+func (s *Scheduler) listen(t *TaskConfig, quit <-chan struct{}) {
+	for {
+		select {
+		case _, ok := <-t.triggers:
+			if !ok {
+				return
+			}
+			s.lock.Lock()
+			s.triggerLocked(t)
+			s.lock.Unlock()
+		case <-quit:
+			return
+		}
+	}
+}
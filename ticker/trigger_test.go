@@ -0,0 +1,88 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrigger(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig { return s.OnTrigger() })
+	defer r.s.Stop()
+
+	if info := r.s.Tasks()[0]; !info.NextRun.IsZero() || !info.Schedule.Trigger {
+		t.Fatalf("unexpected snapshot %+v", info)
+	}
+
+	if err := r.config.Trigger(); err != nil {
+		t.Fatal(err)
+	}
+	r.expectRunAt(date(t, "2020-03-10 10:00"))
+
+	other := r.s.Every().Hour()
+	go other.Do(func(*Task) {}, nil)
+	r.waitFor("the other task", func() bool { return len(r.s.Tasks()) == 2 })
+	if err := r.s.Trigger(other.id); err != ErrNotTriggered {
+		t.Fatalf("expected ErrNotTriggered, got %v", err)
+	}
+}
+
+func TestTrigger_Debounce(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig { return s.OnTrigger().Debounce(10 * time.Second) })
+	defer r.s.Stop()
+
+	// each trigger waits for 10 more seconds of quiet
+	for i := 0; i < 3; i++ {
+		if err := r.config.Trigger(); err != nil {
+			t.Fatal(err)
+		}
+		r.advance(5 * time.Second)
+	}
+	r.expectNoRun()
+
+	r.advance(5 * time.Second)
+	r.expectRunAt(date(t, "2020-03-10 10:00").Add(20 * time.Second))
+	r.expectNoRun()
+}
+
+func TestTrigger_Throttle(t *testing.T) {
+	triggers := make(chan struct{})
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.OnTrigger().Throttle(time.Minute).TriggeredBy(triggers)
+	})
+	defer r.s.Stop()
+
+	// the first trigger runs right away and the next ones are merged into a run a minute later
+	triggers <- struct{}{}
+	r.expectRunAt(date(t, "2020-03-10 10:00"))
+	for i := 0; i < 3; i++ {
+		r.advance(10 * time.Second)
+		triggers <- struct{}{}
+	}
+	r.waitArmed()
+	if next := r.s.Tasks()[0].NextRun; !next.Equal(date(t, "2020-03-10 10:01")) {
+		t.Fatalf("expected the next run at 10:01, got %s", next)
+	}
+
+	r.advance(30 * time.Second)
+	r.expectRunAt(date(t, "2020-03-10 10:01"))
+	r.expectNoRun()
+}
+
+func TestTrigger_AtLeastEvery(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig { return s.Every(6).Hours().OrOnTrigger() })
+	defer r.s.Stop()
+
+	// a trigger postpones the scheduled run
+	r.advance(2 * time.Hour)
+	if err := r.config.Trigger(); err != nil {
+		t.Fatal(err)
+	}
+	r.expectRunAt(date(t, "2020-03-10 12:00"))
+	if next := r.s.Tasks()[0].NextRun; !next.Equal(date(t, "2020-03-10 18:00")) {
+		t.Fatalf("expected the next run at 18:00, got %s", next)
+	}
+
+	// without triggers it runs every 6 hours
+	r.advance(6 * time.Hour)
+	r.expectRunAt(date(t, "2020-03-10 18:00"))
+}
@@ -0,0 +1,234 @@
+package ticker
+
+import (
+	"fmt"
+	"time"
+)
+
+// window is a daily period of time in minutes from midnight. It crosses midnight if to isn't after from.
+type window struct {
+	from, to int
+}
+
+func newWindow(fromHour, fromMinute, toHour, toMinute int) window {
+	return window{from: fromHour*60 + fromMinute, to: toHour*60 + toMinute}
+}
+
+func parseWindow(s string) (w window, err error) {
+	var fromHour, fromMinute, toHour, toMinute int
+	if _, err = fmt.Sscanf(s, "%d:%d-%d:%d", &fromHour, &fromMinute, &toHour, &toMinute); err != nil {
+		return w, fmt.Errorf("invalid window %q: %v", s, err)
+	}
+	return newWindow(fromHour, fromMinute, toHour, toMinute), nil
+}
+
+func (w window) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.from/60, w.from%60, w.to/60, w.to%60)
+}
+
+// on returns the start and end of the window that starts on the day of t
+func (w window) on(t time.Time, days int) (start, end time.Time) {
+	loc := t.Location()
+	y, m, d := t.Date()
+	start = wallTime(y, m, d+days, w.from/60, w.from%60, 0, loc)
+
+	if w.to <= w.from {
+		days++
+	}
+	end = wallTime(y, m, d+days, w.to/60, w.to%60, 0, loc)
+
+	return
+}
+
+// around returns the end of the window that t is in. ok is false if t isn't in the window.
+func (w window) around(t time.Time) (end time.Time, ok bool) {
+	// the window of yesterday may cross midnight
+	for days := -1; days <= 0; days++ {
+		start, end := w.on(t, days)
+		if !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+
+	return
+}
+
+// next returns the start of the first window after t
+func (w window) next(t time.Time) time.Time {
+	start, _ := w.on(t, 0)
+	if !start.After(t) {
+		start, _ = w.on(t, 1)
+	}
+	return start
+}
+
+// Between limits the runs of the task to a daily window from fromHour:fromMinute to toHour:toMinute in the
+// task's time zone. The window crosses midnight if its end isn't after its start. Runs that would happen at the end of
+// the window or outside of it are moved to the start of the next window, or to the next run of the schedule after it
+// for tasks that run every day or longer. Calling it more than once allows all of the windows.
+func (t *TaskConfig) Between(fromHour, fromMinute, toHour, toMinute int) *TaskConfig {
+	t.windows = append(t.windows, newWindow(fromHour, fromMinute, toHour, toMinute))
+	return t
+}
+
+// Except skips the runs of the task that fall into the blackouts with names, which are registered by
+// Scheduler.RegisterBlackout. Like windows, they're moved to after the blackout.
+func (t *TaskConfig) Except(names ...string) *TaskConfig {
+	t.blackouts = append(t.blackouts, names...)
+	return t
+}
+
+// Blackout is a set of periods in which tasks must not run
+type Blackout interface {
+	// Until returns the end of the blackout period that contains at, or at if it's not in a blackout period
+	Until(at time.Time) time.Time
+}
+
+// RegisterBlackout names a blackout so tasks can refer to it by Except. Tasks that refer to unknown names ignore them.
+func (s *Scheduler) RegisterBlackout(name string, b Blackout) *Scheduler {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.blackouts[name] = b
+	s.notify()
+	return s
+}
+
+// allowedFrom returns the first time at or after at that the task is allowed to run. Scheduler must be locked.
+func (s *Scheduler) allowedFrom(t *TaskConfig, at time.Time) time.Time {
+	if len(t.windows) == 0 && len(t.blackouts) == 0 {
+		return at
+	}
+
+	for steps := 0; steps < maxCalendarSteps; steps++ {
+		next := t.inWindow(at.In(t.location(at)))
+		for _, name := range t.blackouts {
+			if b := s.blackouts[name]; b != nil {
+				next = b.Until(next)
+			}
+		}
+
+		if next.Equal(at) {
+			return at
+		}
+		at = next
+	}
+
+	return time.Time{}
+}
+
+// inWindow returns at if it's in one of the task's windows or the start of the next one
+func (t *TaskConfig) inWindow(at time.Time) time.Time {
+	if len(t.windows) == 0 {
+		return at
+	}
+
+	var next time.Time
+	for _, w := range t.windows {
+		if _, ok := w.around(at); ok {
+			return at
+		}
+		if start := w.next(at); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+
+	return next
+}
+
+// nextAllowed returns the first run of the schedule from next that's allowed. Scheduler must be locked.
+func (s *Scheduler) nextAllowed(t *TaskConfig, next time.Time) time.Time {
+	for steps := 0; steps < maxCalendarSteps && !next.IsZero(); steps++ {
+		at := s.allowedFrom(t, next)
+		if at.Equal(next) || at.IsZero() {
+			return at
+		}
+
+		if t.cron == nil && t.unit < unitDays && !t.aligned {
+			// intervals restart when they're allowed
+			return at
+		}
+		next = t.nextRun(next, at.Add(-time.Nanosecond))
+	}
+
+	return time.Time{}
+}
+
+// Calendar is a Blackout made of daily periods, whole days like holidays and arbitrary periods.
+// It must not be changed after it's registered.
+type Calendar struct {
+	loc     *time.Location
+	daily   []window
+	dates   map[string]bool
+	yearly  map[string]bool
+	periods [][2]time.Time
+}
+
+// NewCalendar creates an empty calendar which counts days and hours in loc.
+// If loc is nil, they're counted in the location of the checked time, which is the time zone of the task.
+func NewCalendar(loc *time.Location) *Calendar {
+	return &Calendar{
+		loc:    loc,
+		dates:  make(map[string]bool),
+		yearly: make(map[string]bool),
+	}
+}
+
+// Daily adds a period from fromHour:fromMinute to toHour:toMinute of every day. It crosses midnight if its end isn't
+// after its start.
+func (c *Calendar) Daily(fromHour, fromMinute, toHour, toMinute int) *Calendar {
+	c.daily = append(c.daily, newWindow(fromHour, fromMinute, toHour, toMinute))
+	return c
+}
+
+// Dates adds whole days, like a list of holidays. Only the year, month and day of dates are used.
+func (c *Calendar) Dates(dates ...time.Time) *Calendar {
+	for _, d := range dates {
+		c.dates[d.Format("2006-01-02")] = true
+	}
+	return c
+}
+
+// Yearly adds a day that repeats every year, like new year's day
+func (c *Calendar) Yearly(month time.Month, day int) *Calendar {
+	c.yearly[fmt.Sprintf("%02d-%02d", month, day)] = true
+	return c
+}
+
+// Period adds the period from from to to
+func (c *Calendar) Period(from, to time.Time) *Calendar {
+	c.periods = append(c.periods, [2]time.Time{from, to})
+	return c
+}
+
+func (c *Calendar) Until(at time.Time) time.Time {
+	if c.loc != nil {
+		at = at.In(c.loc)
+	}
+
+	// periods may follow each other, like a holiday and the night after it
+	for steps := 0; steps < maxCalendarSteps; steps++ {
+		next := at
+		if day := at.Format("2006-01-02"); c.dates[day] || c.yearly[day[5:]] {
+			y, m, d := at.Date()
+			next = wallTime(y, m, d+1, 0, 0, 0, at.Location())
+		}
+		for _, w := range c.daily {
+			if end, ok := w.around(at); ok && end.After(next) {
+				next = end
+			}
+		}
+		for _, p := range c.periods {
+			if !at.Before(p[0]) && at.Before(p[1]) && p[1].After(next) {
+				next = p[1].In(at.Location())
+			}
+		}
+
+		if next.Equal(at) {
+			return at
+		}
+		at = next
+	}
+
+	return at
+}
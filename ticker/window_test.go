@@ -0,0 +1,73 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindows(t *testing.T) {
+	expectSchedule(t, "daily window", "2020-03-10 17:45", func(s *Scheduler) *TaskConfig {
+		return s.Every(5).Minutes().Between(8, 0, 18, 0)
+	}, "2020-03-10 17:50", "2020-03-10 17:55", "2020-03-11 08:00", "2020-03-11 08:05")
+
+	expectSchedule(t, "window across midnight", "2020-03-10 20:30", func(s *Scheduler) *TaskConfig {
+		return s.Every().Hour().Between(22, 0, 2, 0)
+	}, "2020-03-10 22:00", "2020-03-10 23:00", "2020-03-11 00:00", "2020-03-11 01:00", "2020-03-11 22:00")
+
+	expectSchedule(t, "several windows", "2020-03-10 07:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(2).Hours().Between(8, 0, 10, 0).Between(14, 0, 15, 0)
+	}, "2020-03-10 09:00", "2020-03-10 14:00", "2020-03-11 08:00")
+
+	// a daily task at a time outside of its window never runs
+	s := NewScheduler(1).WithClock(NewFakeClock(date(t, "2020-03-10 00:00")))
+	task := s.Every().Day().At(7, 0).Between(8, 0, 18, 0)
+	if next := s.nextAllowed(task, task.nextRun(time.Time{}, date(t, "2020-03-10 00:00"))); !next.IsZero() {
+		t.Errorf("expected no runs, got %s", next)
+	}
+}
+
+func TestBlackouts(t *testing.T) {
+	expectSchedule(t, "maintenance", "2020-03-10 21:30", func(s *Scheduler) *TaskConfig {
+		s.RegisterBlackout("maintenance", NewCalendar(nil).Daily(23, 0, 1, 0))
+		return s.Every().Hour().Except("maintenance")
+	}, "2020-03-10 22:30", "2020-03-11 01:00", "2020-03-11 02:00")
+
+	expectSchedule(t, "holidays", "2020-12-23 12:00", func(s *Scheduler) *TaskConfig {
+		s.RegisterBlackout("holidays", NewCalendar(time.UTC).
+			Dates(date(t, "2020-12-25 00:00"), date(t, "2020-12-28 00:00")).
+			Yearly(time.January, 1))
+		return s.Every().Day().At(9, 0).Except("holidays")
+	}, "2020-12-24 09:00", "2020-12-26 09:00", "2020-12-27 09:00", "2020-12-29 09:00",
+		"2020-12-30 09:00", "2020-12-31 09:00", "2021-01-02 09:00")
+
+	expectSchedule(t, "weekly on a holiday", "2020-12-20 12:00", func(s *Scheduler) *TaskConfig {
+		s.RegisterBlackout("holidays", NewCalendar(nil).Dates(date(t, "2020-12-28 00:00")))
+		return s.Every().Monday().At(9, 0).Except("holidays")
+	}, "2020-12-21 09:00", "2021-01-04 09:00")
+
+	expectSchedule(t, "periods that follow each other", "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		s.RegisterBlackout("freeze", NewCalendar(nil).
+			Period(date(t, "2020-03-10 11:00"), date(t, "2020-03-11 00:00")).
+			Dates(date(t, "2020-03-11 00:00")))
+		s.RegisterBlackout("maintenance", NewCalendar(nil).Daily(0, 0, 2, 0))
+		return s.Every(30).Minutes().Except("freeze", "maintenance")
+	}, "2020-03-10 10:30", "2020-03-12 02:00", "2020-03-12 02:30")
+
+	expectSchedule(t, "unknown names are ignored", "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Hour().Except("unknown")
+	}, "2020-03-10 11:00")
+}
+
+func TestBlackouts_Registered(t *testing.T) {
+	r := startFake(t, "2020-03-10 10:00", func(s *Scheduler) *TaskConfig {
+		return s.Every(30).Minutes().Except("freeze")
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2020-03-10 10:30", true)
+	// the next run is already scheduled when the blackout is registered
+	r.s.RegisterBlackout("freeze", NewCalendar(nil).Period(date(t, "2020-03-10 10:45"), date(t, "2020-03-10 12:10")))
+	r.advanceTo("2020-03-10 11:00", false)
+	r.advanceTo("2020-03-10 12:10", true)
+	r.advanceTo("2020-03-10 12:40", true)
+}
@@ -0,0 +1,330 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrWorkflowCycle is returned when adding a node to a workflow would make it depend on itself
+var ErrWorkflowCycle = errors.New("ticker: workflow has a cycle")
+
+// NodeState is the state of a node in a run of a workflow
+type NodeState string
+
+const (
+	NodePending   NodeState = "pending"
+	NodeRunning   NodeState = "running"
+	NodeSucceeded NodeState = "succeeded"
+	NodeFailed    NodeState = "failed"
+	NodeSkipped   NodeState = "skipped" // one of the parents didn't succeed or the run was cancelled
+)
+
+// NodeStatus is the state of a node in a run of a workflow
+type NodeStatus struct {
+	Name    string
+	Parents []string
+	State   NodeState
+	Start   time.Time
+	End     time.Time
+	Err     error
+	Payload interface{} // payload that the node's handler left in Task.Payload
+}
+
+// WorkflowRun is a snapshot of a run of a workflow
+type WorkflowRun struct {
+	Id    uuid.UUID
+	Start time.Time
+	End   time.Time // zero while the run is running
+	Nodes []NodeStatus
+	Err   error
+}
+
+// Node returns the status of the node with name
+func (r WorkflowRun) Node(name string) (NodeStatus, bool) {
+	for _, n := range r.Nodes {
+		if n.Name == name {
+			return n, true
+		}
+	}
+	return NodeStatus{}, false
+}
+
+type workflowNode struct {
+	name    string
+	parents []string
+	handler ContextTaskFunc
+}
+
+// Workflow is a set of named handlers that depend on each other. A run of the workflow runs each node after all of
+// its parents succeed, and nodes that don't depend on each other at the same time. Workflow.Run is a ContextTaskFunc,
+// so workflows are scheduled like any other task and each run of the task is a run of the whole workflow:
+//
+//	w := ticker.NewWorkflow("nightly")
+//	w.Add("import", importData)
+//	w.Add("report", generateReport, "import")
+//	go ticker.Every().Day().At(2, 0).Name("nightly").DoContext(w.Run, nil)
+type Workflow struct {
+	name  string
+	lock  sync.Mutex
+	nodes map[string]*workflowNode
+	order []string // names in the order they were added
+	last  *WorkflowRun
+}
+
+func NewWorkflow(name string) *Workflow {
+	return &Workflow{
+		name:  name,
+		nodes: make(map[string]*workflowNode),
+	}
+}
+
+// Add adds a node that runs f after all of parents succeed. Parents can be added later, but they must be added before
+// the workflow runs. Each node gets the payloads of its parents in Task.Parents and leaves its own payload in
+// Task.Payload. Add returns an error wrapping ErrWorkflowCycle if the node would depend on itself.
+func (w *Workflow) Add(name string, f ContextTaskFunc, parents ...string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if _, ok := w.nodes[name]; ok {
+		return fmt.Errorf("ticker: workflow %s already has node %s", w.name, name)
+	}
+
+	for _, p := range parents {
+		if p == name || w.dependsOn(p, name, make(map[string]bool)) {
+			return fmt.Errorf("%w: %s depends on %s through %s in workflow %s", ErrWorkflowCycle, name, name, p, w.name)
+		}
+	}
+
+	w.nodes[name] = &workflowNode{name: name, parents: parents, handler: f}
+	w.order = append(w.order, name)
+	return nil
+}
+
+// dependsOn reports whether node depends on target through its parents
+func (w *Workflow) dependsOn(node, target string, seen map[string]bool) bool {
+	if seen[node] {
+		return false
+	}
+	seen[node] = true
+
+	n, ok := w.nodes[node]
+	if !ok {
+		return false
+	}
+
+	for _, p := range n.parents {
+		if p == target || w.dependsOn(p, target, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LastRun returns the snapshot of the last run of the workflow, which may still be running
+func (w *Workflow) LastRun() (WorkflowRun, bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.last == nil {
+		return WorkflowRun{}, false
+	}
+
+	r := *w.last
+	r.Nodes = append([]NodeStatus(nil), r.Nodes...)
+	return r, true
+}
+
+// nodeResult is a node that finished running
+type nodeResult struct {
+	index   int
+	err     error
+	payload interface{}
+}
+
+// Run runs the workflow once. It returns an error if any of the nodes fails.
+func (w *Workflow) Run(ctx context.Context, task *Task) error {
+	now := time.Now
+	if task.config != nil && task.config.scheduler != nil {
+		now = task.config.scheduler.clock.Now
+	}
+
+	w.lock.Lock()
+	run, handlers, err := w.newRun(now())
+	if err != nil {
+		w.lock.Unlock()
+		return err
+	}
+	w.last = run
+
+	index := make(map[string]int, len(run.Nodes))
+	for i, n := range run.Nodes {
+		index[n.Name] = i
+	}
+	children := make([][]int, len(run.Nodes))
+	waiting := make([]int, len(run.Nodes)) // number of parents that haven't finished
+	for i, n := range run.Nodes {
+		waiting[i] = len(n.Parents)
+		for _, p := range n.Parents {
+			children[index[p]] = append(children[index[p]], i)
+		}
+	}
+	w.lock.Unlock()
+
+	results := make(chan nodeResult)
+	running := 0
+	start := func(i int) {
+		w.lock.Lock()
+		status := &run.Nodes[i]
+		if ctx.Err() != nil {
+			status.State = NodeSkipped
+			status.Err = ctx.Err()
+			w.lock.Unlock()
+			return
+		}
+
+		parents := make(map[string]interface{}, len(status.Parents))
+		for _, p := range status.Parents {
+			parents[p] = run.Nodes[index[p]].Payload
+		}
+		nodeTask := *task
+		nodeTask.Parents = parents
+		status.State = NodeRunning
+		status.Start = now()
+		w.lock.Unlock()
+
+		running++
+		go func() {
+			_, err := call(ctx, handlers[i], &nodeTask)
+			results <- nodeResult{index: i, err: err, payload: nodeTask.Payload}
+		}()
+	}
+
+	// finished nodes are handled in order so their children start or get skipped
+	var finished []int
+	for i := range run.Nodes {
+		if waiting[i] == 0 {
+			start(i)
+			if run.Nodes[i].State == NodeSkipped {
+				finished = append(finished, i)
+			}
+		}
+	}
+
+	for running > 0 || len(finished) > 0 {
+		if len(finished) == 0 {
+			r := <-results
+			running--
+
+			w.lock.Lock()
+			status := &run.Nodes[r.index]
+			status.End = now()
+			status.Err = r.err
+			status.Payload = r.payload
+			status.State = NodeSucceeded
+			if r.err != nil {
+				status.State = NodeFailed
+			}
+			w.lock.Unlock()
+
+			finished = append(finished, r.index)
+			continue
+		}
+
+		i := finished[0]
+		finished = finished[1:]
+		for _, c := range children[i] {
+			if waiting[c]--; waiting[c] > 0 {
+				continue
+			}
+
+			if w.parentsSucceeded(run, index, c) {
+				start(c)
+				if run.Nodes[c].State != NodeSkipped {
+					continue
+				}
+			} else {
+				w.lock.Lock()
+				run.Nodes[c].State = NodeSkipped
+				w.lock.Unlock()
+			}
+			finished = append(finished, c)
+		}
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	run.End = now()
+	var failed []string
+	for _, n := range run.Nodes {
+		if n.State == NodeFailed {
+			failed = append(failed, n.Name)
+		}
+	}
+	if len(failed) > 0 {
+		run.Err = fmt.Errorf("ticker: nodes of workflow %s failed: %s", w.name, strings.Join(failed, ", "))
+	} else if err := ctx.Err(); err != nil {
+		run.Err = err
+	}
+
+	return run.Err
+}
+
+// newRun checks the nodes and makes a run with all of them pending in the order they can run, along with their
+// handlers. Workflow must be locked.
+func (w *Workflow) newRun(start time.Time) (*WorkflowRun, []ContextTaskFunc, error) {
+	for _, name := range w.order {
+		for _, p := range w.nodes[name].parents {
+			if _, ok := w.nodes[p]; !ok {
+				return nil, nil, fmt.Errorf("ticker: node %s of workflow %s depends on unknown node %s", name, w.name, p)
+			}
+		}
+	}
+
+	id, _ := uuid.NewRandom()
+	run := &WorkflowRun{Id: id, Start: start, Nodes: make([]NodeStatus, 0, len(w.nodes))}
+	handlers := make([]ContextTaskFunc, 0, len(w.nodes))
+
+	// parents come before children
+	added := make(map[string]bool, len(w.nodes))
+	for len(run.Nodes) < len(w.nodes) {
+		for _, name := range w.order {
+			n := w.nodes[name]
+			if added[name] {
+				continue
+			}
+
+			ready := true
+			for _, p := range n.parents {
+				ready = ready && added[p]
+			}
+			if ready {
+				added[name] = true
+				run.Nodes = append(run.Nodes, NodeStatus{Name: name, Parents: n.parents, State: NodePending})
+				handlers = append(handlers, n.handler)
+			}
+		}
+	}
+
+	return run, handlers, nil
+}
+
+// parentsSucceeded reports whether all parents of the node at i succeeded
+func (w *Workflow) parentsSucceeded(run *WorkflowRun, index map[string]int, i int) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, p := range run.Nodes[i].Parents {
+		if run.Nodes[index[p]].State != NodeSucceeded {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkflow_Cycle(t *testing.T) {
+	w := NewWorkflow("test")
+	noop := func(context.Context, *Task) error { return nil }
+
+	for _, err := range []error{
+		w.Add("a", noop, "c"),
+		w.Add("b", noop, "a"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Add("c", noop, "b"); !errors.Is(err, ErrWorkflowCycle) {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+	if err := w.Add("d", noop, "d"); !errors.Is(err, ErrWorkflowCycle) {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+	if err := w.Add("a", noop); err == nil || errors.Is(err, ErrWorkflowCycle) {
+		t.Fatalf("expected a duplicate error, got %v", err)
+	}
+
+	// c was never added
+	if err := w.Run(context.Background(), &Task{}); err == nil {
+		t.Fatal("expected an error for the unknown node")
+	}
+}
+
+func TestWorkflow_Run(t *testing.T) {
+	w := NewWorkflow("test")
+
+	// b and c wait for each other to start, so they must run at the same time
+	var started sync.WaitGroup
+	started.Add(2)
+	parallel := func(n int) ContextTaskFunc {
+		return func(ctx context.Context, task *Task) error {
+			started.Done()
+			started.Wait()
+			task.Payload = task.Parents["a"].(int) + n
+			return nil
+		}
+	}
+
+	adds := []error{
+		w.Add("d", func(ctx context.Context, task *Task) error {
+			task.Payload = task.Parents["b"].(int) * task.Parents["c"].(int)
+			return nil
+		}, "b", "c"),
+		w.Add("a", func(ctx context.Context, task *Task) error {
+			task.Payload = task.Payload.(int) + 1
+			return nil
+		}),
+		w.Add("b", parallel(1), "a"),
+		w.Add("c", parallel(2), "a"),
+	}
+	for _, err := range adds {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Run(context.Background(), &Task{Payload: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	run, ok := w.LastRun()
+	if !ok || run.Err != nil || run.End.IsZero() {
+		t.Fatalf("unexpected run %+v", run)
+	}
+
+	// nodes are listed in the order they can run
+	expected := []string{"a", "b", "c", "d"}
+	for i, n := range run.Nodes {
+		if n.Name != expected[i] || n.State != NodeSucceeded {
+			t.Fatalf("unexpected node %d %+v", i, n)
+		}
+	}
+	if d, _ := run.Node("d"); d.Payload != 12 {
+		t.Fatalf("expected 12 from d, got %v", d.Payload)
+	}
+}
+
+func TestWorkflow_Failure(t *testing.T) {
+	w := NewWorkflow("test")
+	noop := func(context.Context, *Task) error { return nil }
+
+	adds := []error{
+		w.Add("a", noop),
+		w.Add("b", func(context.Context, *Task) error { return errors.New("failed") }, "a"),
+		w.Add("c", func(context.Context, *Task) error { panic("c") }, "a"),
+		w.Add("d", noop, "a"),
+		w.Add("e", noop, "b", "d"),
+		w.Add("f", noop, "e"),
+	}
+	for _, err := range adds {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Run(context.Background(), &Task{}); err == nil || err.Error() != "ticker: nodes of workflow test failed: b, c" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	run, _ := w.LastRun()
+	states := map[string]NodeState{
+		"a": NodeSucceeded,
+		"b": NodeFailed,
+		"c": NodeFailed,
+		"d": NodeSucceeded,
+		"e": NodeSkipped,
+		"f": NodeSkipped,
+	}
+	for name, state := range states {
+		if n, _ := run.Node(name); n.State != state {
+			t.Errorf("expected %s to be %s, got %+v", name, state, n)
+		}
+	}
+
+	var p *PanicError
+	if c, _ := run.Node("c"); !errors.As(c.Err, &p) || p.Value != "c" {
+		t.Errorf("expected a panic error from c, got %v", c.Err)
+	}
+}
+
+func TestWorkflow_Scheduled(t *testing.T) {
+	clock := NewFakeClock(date(t, "2020-03-10 10:00"))
+	s := NewScheduler(10).WithClock(clock)
+	s.Start()
+	defer s.Stop()
+
+	w := NewWorkflow("nightly")
+	done := make(chan struct{}, 10)
+	adds := []error{
+		w.Add("import", func(ctx context.Context, task *Task) error {
+			clock.Advance(time.Second)
+			return nil
+		}),
+		w.Add("report", func(ctx context.Context, task *Task) error {
+			done <- struct{}{}
+			return errors.New("failed")
+		}, "import"),
+	}
+	for _, err := range adds {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task := s.Every().Hour().Name("nightly")
+	go task.DoContext(w.Run, nil)
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected a run of the workflow")
+	}
+
+	// the task fails as a whole
+	deadline := time.Now().Add(time.Second)
+	for s.Tasks()[0].Runs == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if info := s.Tasks()[0]; info.Runs != 1 || info.Failures != 1 {
+		t.Fatalf("unexpected snapshot %+v", info)
+	}
+
+	run, _ := w.LastRun()
+	if i, _ := run.Node("import"); !i.Start.Equal(date(t, "2020-03-10 11:00")) || i.End.Sub(i.Start) != time.Second {
+		t.Fatalf("unexpected import node %+v", i)
+	}
+}
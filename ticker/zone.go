@@ -0,0 +1,59 @@
+package ticker
+
+import "time"
+
+// In sets the time zone that the task's days, weekdays and hours are counted in.
+// By default they're counted in the location of the scheduler's clock.
+//
+// Around daylight saving time changes, a time that doesn't exist because clocks are turned forward runs later by
+// the length of the gap (02:30 runs at 03:30) and a time that happens twice because clocks are turned back runs
+// only at its first occurrence. Intervals of hours and shorter are counted in elapsed time and aren't affected.
+func (t *TaskConfig) In(loc *time.Location) *TaskConfig {
+	t.loc = loc
+	return t
+}
+
+// location returns the time zone of the task or the location of now if it has none
+func (t *TaskConfig) location(now time.Time) *time.Location {
+	if t.loc != nil {
+		return t.loc
+	}
+	return now.Location()
+}
+
+// wallTime returns the time that the clocks of loc show the given date and time.
+// Unlike time.Date, it resolves daylight saving time gaps and overlaps as documented by TaskConfig.In.
+func wallTime(year int, month time.Month, day, hour, minute, second int, loc *time.Location) time.Time {
+	naive := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	matches := func(t time.Time) bool {
+		return t.Hour() == naive.Hour() && t.Minute() == naive.Minute() && t.Second() == naive.Second()
+	}
+
+	// offsets of loc a while before and after the time; they're different if there's a transition in between
+	_, before := naive.Add(-12 * time.Hour).In(loc).Zone()
+	_, after := naive.Add(12 * time.Hour).In(loc).Zone()
+
+	// with the offset before the transition, this is the first occurrence of the time or the time moved past a gap
+	t := naive.Add(-time.Duration(before) * time.Second).In(loc)
+	if matches(t) {
+		return t
+	}
+
+	if u := naive.Add(-time.Duration(after) * time.Second).In(loc); matches(u) {
+		return u
+	}
+
+	return t
+}
+
+// repeatedWallTime reports whether the clock showed the same time earlier because it was turned back
+func repeatedWallTime(t time.Time) bool {
+	_, offset := t.Zone()
+	_, before := t.Add(-12 * time.Hour).Zone()
+	if before <= offset {
+		return false
+	}
+
+	u := t.Add(-time.Duration(before-offset) * time.Second)
+	return u.Hour() == t.Hour() && u.Minute() == t.Minute() && u.Second() == t.Second()
+}
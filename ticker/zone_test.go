@@ -0,0 +1,136 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s isn't available: %v", name, err)
+	}
+	return loc
+}
+
+func TestWallTime(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	cases := []struct {
+		name     string
+		date     string
+		expected string // in UTC
+	}{
+		{"regular", "2021-06-01 09:00", "2021-06-01 07:00"},
+		{"before spring forward", "2021-03-28 01:30", "2021-03-28 00:30"},
+		{"in the gap", "2021-03-28 02:30", "2021-03-28 01:30"}, // 03:30 CEST
+		{"after spring forward", "2021-03-28 03:30", "2021-03-28 01:30"},
+		{"repeated", "2021-10-31 02:30", "2021-10-31 00:30"}, // first occurrence, in CEST
+		{"after fall back", "2021-10-31 09:00", "2021-10-31 08:00"},
+	}
+
+	for _, c := range cases {
+		d := date(t, c.date)
+		got := wallTime(d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), 0, berlin)
+		if !got.Equal(date(t, c.expected)) {
+			t.Errorf("%s: expected %s UTC, got %s", c.name, c.expected, got.UTC())
+		}
+	}
+}
+
+func TestIn(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	// clock is in UTC, the task runs at 09:00 in Berlin
+	r := startFake(t, "2021-01-10 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Day().At(9, 0).In(berlin)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2021-01-10 07:59", false)
+	r.advanceTo("2021-01-10 08:00", true)
+	r.advanceTo("2021-01-11 08:00", true)
+}
+
+func TestIn_SpringForward(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	r := startFake(t, "2021-03-26 12:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Day().At(2, 30).In(berlin)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2021-03-27 01:30", true) // 02:30 CET
+	// 02:30 doesn't exist on the 28th, it runs at 03:30 CEST
+	r.advanceTo("2021-03-28 01:29", false)
+	r.advanceTo("2021-03-28 01:30", true)
+	r.advanceTo("2021-03-29 00:29", false)
+	r.advanceTo("2021-03-29 00:30", true) // 02:30 CEST
+}
+
+func TestIn_FallBack(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	r := startFake(t, "2021-10-29 12:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Day().At(2, 30).In(berlin)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2021-10-30 00:30", true) // 02:30 CEST
+	// 02:30 happens twice on the 31st, it runs at the first one only
+	r.advanceTo("2021-10-31 00:30", true)
+	r.advanceTo("2021-10-31 01:30", false)
+	r.advanceTo("2021-11-01 01:30", true) // 02:30 CET
+}
+
+func TestIn_Weekly(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+
+	// clocks are turned forward on sunday 2021-03-14
+	r := startFake(t, "2021-03-01 00:00", func(s *Scheduler) *TaskConfig {
+		return s.Every().Monday().At(9, 0).In(newYork)
+	})
+	defer r.s.Stop()
+
+	r.advanceTo("2021-03-08 14:00", true) // 09:00 EST
+	r.advanceTo("2021-03-15 13:00", true) // 09:00 EDT
+}
+
+func TestCron_DST(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	cases := []struct {
+		name, expr, from string
+		expected         []string // in UTC
+	}{
+		{
+			name: "spring forward", expr: "30 2 * * *", from: "2021-03-27 12:00",
+			expected: []string{"2021-03-28 01:30", "2021-03-29 00:30"},
+		},
+		{
+			name: "fall back", expr: "30 2 * * *", from: "2021-10-30 12:00",
+			expected: []string{"2021-10-31 00:30", "2021-11-01 01:30"},
+		},
+		{
+			// tasks that run every hour keep running through the repeated hour
+			name: "fall back hourly", expr: "30 * * * *", from: "2021-10-30 23:45",
+			expected: []string{"2021-10-31 00:30", "2021-10-31 01:30", "2021-10-31 02:30"},
+		},
+	}
+
+	for _, c := range cases {
+		cron, err := parseCron(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		next := date(t, c.from).In(berlin)
+		for _, expected := range c.expected {
+			next = cron.next(next)
+			if !next.Equal(date(t, expected)) {
+				t.Errorf("%s: expected %s UTC, got %s", c.name, expected, next.UTC())
+				break
+			}
+		}
+	}
+}